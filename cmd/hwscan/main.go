@@ -5,26 +5,45 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/Lexharden/hwscan/internal/export"
 	"github.com/Lexharden/hwscan/internal/hardware"
+	"github.com/Lexharden/hwscan/internal/ids"
 	"github.com/Lexharden/hwscan/internal/server"
 	"github.com/Lexharden/hwscan/internal/version"
 )
 
 func main() {
+	// Subcomando capture: graba la salida de los comandos externos junto al JSON
+	if len(os.Args) > 1 && os.Args[1] == "capture" {
+		runCapture(os.Args[2:])
+		return
+	}
+
 	// Flags de línea de comandos
 	portFlag := flag.Int("port", 8080, "Puerto para el servidor web")
 	noServerFlag := flag.Bool("no-server", false, "Desactivar servidor web")
 	noExportFlag := flag.Bool("no-export", false, "Desactivar exportación automática")
 	outputFlag := flag.String("output", "", "Ruta específica para exportar JSON")
+	rootFlag := flag.String("root", "/", "Directorio raíz con /proc y /sys a escanear")
+	replayFlag := flag.String("replay", "", "Directorio con salidas de comandos grabadas por 'hwscan capture'")
+	pciIDsFlag := flag.String("pci-ids", "", "Ruta a una base de datos pci.ids (o pci.ids.gz)")
+	usbIDsFlag := flag.String("usb-ids", "", "Ruta a una base de datos usb.ids (o usb.ids.gz)")
+	timeoutFlag := flag.Duration("timeout", 15*time.Second, "Tiempo límite de cada detector (0 = sin límite)")
+	detectorTimeoutsFlag := flag.String("detector-timeouts", "", "Tiempos límite por detector (ej: gpu=30s,memory=5s)")
+	sensorSamplesFlag := flag.Int("sensor-samples", 0, "Muestras de sensores a tomar tras la detección (0 = solo la lectura inicial)")
+	sensorIntervalFlag := flag.Duration("sensor-interval", time.Second, "Intervalo entre muestras de sensores")
+	batteryThresholdFlag := flag.Float64("battery-threshold", hardware.DefaultBatteryHealthThreshold, "Salud mínima de batería en % antes de marcarla")
 	versionFlag := flag.Bool("version", false, "Mostrar versión")
 	helpFlag := flag.Bool("help", false, "Mostrar ayuda")
 
@@ -46,11 +65,49 @@ func main() {
 	fmt.Println("Detectando hardware del sistema...")
 	fmt.Println()
 
-	hwInfo, err := hardware.Detect()
+	detector := hardware.NewDetector(*rootFlag)
+	if *replayFlag != "" {
+		detector.Runner = hardware.ReplayRunner{Dir: *replayFlag}
+	}
+	if *pciIDsFlag != "" {
+		db, err := ids.Load(*pciIDsFlag)
+		if err != nil {
+			log.Fatalf("Error al cargar %s: %v\n", *pciIDsFlag, err)
+		}
+		detector.PCIIDs = db
+	}
+	if *usbIDsFlag != "" {
+		db, err := ids.Load(*usbIDsFlag)
+		if err != nil {
+			log.Fatalf("Error al cargar %s: %v\n", *usbIDsFlag, err)
+		}
+		detector.USBIDs = db
+	}
+
+	if *batteryThresholdFlag <= 0 || *batteryThresholdFlag > 100 {
+		log.Fatalf("-battery-threshold debe estar entre 0 y 100\n")
+	}
+	detector.BatteryHealthThreshold = *batteryThresholdFlag
+
+	opts, err := detectOptions(*timeoutFlag, *detectorTimeoutsFlag)
+	if err != nil {
+		log.Fatalf("Error en -detector-timeouts: %v\n", err)
+	}
+
+	hwInfo, err := detector.DetectContext(context.Background(), opts)
 	if err != nil {
 		log.Fatalf("Error al detectar hardware: %v\n", err)
 	}
 
+	if *sensorSamplesFlag > 0 {
+		fmt.Printf("Muestreando sensores (%d muestras cada %s)...\n\n", *sensorSamplesFlag, *sensorIntervalFlag)
+		series, err := detector.SampleSensors(context.Background(), *sensorSamplesFlag, *sensorIntervalFlag)
+		if err != nil {
+			log.Printf("Advertencia: muestreo de sensores incompleto: %v\n", err)
+		}
+		hwInfo.SensorSeries = series
+	}
+
 	// Paso 2: Mostrar información en consola
 	fmt.Print(hardware.FormatConsole(hwInfo))
 	fmt.Println()
@@ -83,7 +140,7 @@ func main() {
 
 	// Paso 4: Iniciar servidor web (si no está desactivado)
 	if !*noServerFlag {
-		srv := server.New(hwInfo, *portFlag)
+		srv := server.New(hwInfo, detector, *portFlag)
 		if err := srv.Start(); err != nil {
 			log.Printf("Advertencia: no se pudo iniciar servidor web: %v\n", err)
 		} else {
@@ -100,6 +157,78 @@ func main() {
 	waitForShutdown()
 }
 
+// detectOptions construye las opciones de detección a partir del tiempo límite
+// global y de la lista "nombre=duración,..." de -detector-timeouts
+func detectOptions(timeout time.Duration, perDetector string) (hardware.DetectOptions, error) {
+	opts := hardware.DetectOptions{
+		Timeout:  timeout,
+		Timeouts: make(map[string]time.Duration),
+	}
+	if perDetector == "" {
+		return opts, nil
+	}
+
+	known := make(map[string]bool)
+	for _, name := range hardware.DetectorNames() {
+		known[name] = true
+	}
+
+	for _, item := range strings.Split(perDetector, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok {
+			return opts, fmt.Errorf("formato inválido %q (se espera nombre=duración)", item)
+		}
+		if !known[name] {
+			return opts, fmt.Errorf("detector desconocido %q (disponibles: %s)",
+				name, strings.Join(hardware.DetectorNames(), ", "))
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return opts, fmt.Errorf("duración inválida para %s: %w", name, err)
+		}
+		opts.Timeouts[name] = d
+	}
+
+	return opts, nil
+}
+
+// runCapture ejecuta la detección grabando la salida de cada comando externo
+// en un directorio junto al JSON exportado, para reproducirla con -replay
+func runCapture(args []string) {
+	fs := flag.NewFlagSet("capture", flag.ExitOnError)
+	dirFlag := fs.String("dir", "", "Directorio de salida (default: hwscan-capture-<fecha>)")
+	rootFlag := fs.String("root", "/", "Directorio raíz con /proc y /sys a escanear")
+	timeoutFlag := fs.Duration("timeout", 15*time.Second, "Tiempo límite de cada detector (0 = sin límite)")
+	fs.Parse(args)
+
+	dir := *dirFlag
+	if dir == "" {
+		dir = "hwscan-capture-" + time.Now().Format("20060102-150405")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Fatalf("Error al crear directorio de captura: %v\n", err)
+	}
+
+	fmt.Println("Detectando hardware y grabando comandos externos...")
+	fmt.Println()
+
+	detector := hardware.NewDetector(*rootFlag)
+	detector.Runner = &hardware.RecordingRunner{Runner: hardware.ExecRunner{}, Dir: dir}
+
+	hwInfo, err := detector.DetectContext(context.Background(), hardware.DetectOptions{Timeout: *timeoutFlag})
+	if err != nil {
+		log.Fatalf("Error al detectar hardware: %v\n", err)
+	}
+
+	jsonPath := filepath.Join(dir, "hwscan.json")
+	if err := export.ExportToJSON(hwInfo, jsonPath); err != nil {
+		log.Fatalf("Error al exportar JSON: %v\n", err)
+	}
+
+	fmt.Printf("Captura guardada en %s\n", dir)
+	fmt.Printf("Reproducir con: hwscan -replay %s\n", dir)
+}
+
 // showHelp muestra la ayuda del programa
 func showHelp() {
 	help := `
@@ -107,12 +236,25 @@ HWSCAN - Hardware Detection Tool
 
 USO:
     hwscan [opciones]
+    hwscan capture [-dir <dir>] [-root <dir>] [-timeout <dur>]
 
 OPCIONES:
     -port <número>      Puerto para el servidor web (default: 8080)
     -no-server          Desactivar servidor web
     -no-export          Desactivar exportación automática a JSON
     -output <ruta>      Ruta específica para exportar JSON
+    -root <dir>         Directorio raíz con /proc y /sys a escanear (default: /)
+    -replay <dir>       Usar salidas de comandos grabadas por 'hwscan capture'
+    -pci-ids <ruta>     Base de datos pci.ids a usar (default: la del sistema o la embebida)
+    -usb-ids <ruta>     Base de datos usb.ids a usar (default: la del sistema o la embebida)
+    -timeout <dur>      Tiempo límite de cada detector (default: 15s, 0 = sin límite)
+    -detector-timeouts <lista>
+                        Tiempos límite por detector (ej: gpu=30s,memory=5s)
+    -sensor-samples <n> Muestras de sensores a tomar tras la detección (default: 0)
+    -sensor-interval <dur>
+                        Intervalo entre muestras de sensores (default: 1s)
+    -battery-threshold <pct>
+                        Salud mínima de batería antes de marcarla (default: 80)
     -version            Mostrar versión del programa
     -help               Mostrar esta ayuda
 
@@ -132,13 +274,39 @@ EJEMPLOS:
     # Solo mostrar en consola
     hwscan -no-server -no-export
 
+    # Escanear una copia de /proc y /sys capturada en otra máquina
+    hwscan -root /tmp/snapshot-cliente -no-server
+
+    # Vigilar temperaturas y ventiladores durante un minuto
+    hwscan -sensor-samples 60 -no-server
+
+    # Marcar como degradadas las baterías por debajo del 70% de su capacidad
+    hwscan -battery-threshold 70 -no-server
+
+    # Grabar dmidecode/lspci/nvidia-smi junto al JSON y reproducirlo después
+    hwscan capture -dir /tmp/captura
+    hwscan -replay /tmp/captura -no-server -no-export
+
 DESCRIPCIÓN:
     HWSCAN detecta automáticamente el hardware del sistema incluyendo:
-    - CPU (modelo, velocidad, núcleos)
+    - CPU (modelo, velocidad, sockets, núcleos P/E, cachés, NUMA)
+    - CPUID nativo en amd64 (características, cachés, hipervisor)
+    - Seguridad del CPU (microcódigo, vulnerabilidades y mitigaciones)
     - Memoria RAM (capacidad, módulos, velocidades)
-	- Disco(s) (modelo, capacidad, tipo)
+    - Disco(s) (modelo, capacidad, tipo, serie, WWN, firmware, salud SMART, particiones)
+    - Almacenamiento (RAID md, LVM y device-mapper, con sus discos)
     - Placa Madre (fabricante, modelo, BIOS)
-    - GPU (tarjetas gráficas instaladas)
+    - Sistema (producto OEM, service tag, chasis, etiqueta de inventario)
+    - Firmware (UEFI o BIOS, Secure Boot, entradas de arranque)
+    - TPM (versión, fabricante, estado, PCR SHA-256)
+    - Plataforma (máquina virtual o contenedor, marcada en el reporte)
+    - GPU (driver, enlace PCIe, nodos DRM, carga y frecuencias)
+    - Monitores (EDID: fabricante, modelo, serie, tamaño, resolución nativa)
+    - Dispositivos PCI (clase, fabricante, driver, grupo IOMMU)
+    - Interfaces de red (MAC, driver, firmware, enlace y velocidad)
+    - Árbol USB (hubs y dispositivos, fabricante, producto, velocidad)
+    - Baterías (salud, desgaste, ciclos) y adaptadores de corriente
+    - Sensores hwmon (temperaturas, ventiladores, tensiones, corrientes, potencias)
 
     La información se muestra en consola, se exporta a JSON y está
     disponible mediante una interfaz web en http://localhost:8080
@@ -0,0 +1,296 @@
+package hardware
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// cpuidFunc ejecuta CPUID para una hoja y subhoja
+type cpuidFunc func(leaf, subleaf uint32) (eax, ebx, ecx, edx uint32)
+
+// cpuidFeature es un bit de característica de CPUID
+type cpuidFeature struct {
+	leaf uint32
+	reg  byte // 'b', 'c' o 'd'
+	bit  uint
+	name string
+}
+
+// cpuidFeatures son los bits de característica que se decodifican, con el
+// mismo nombre que usa el kernel en la línea flags de /proc/cpuinfo para
+// poder compararlos. Hoja 7 = subhoja 0.
+var cpuidFeatures = []cpuidFeature{
+	// Hoja 1, EDX
+	{1, 'd', 0, "fpu"}, {1, 'd', 1, "vme"}, {1, 'd', 2, "de"}, {1, 'd', 3, "pse"},
+	{1, 'd', 4, "tsc"}, {1, 'd', 5, "msr"}, {1, 'd', 6, "pae"}, {1, 'd', 7, "mce"},
+	{1, 'd', 8, "cx8"}, {1, 'd', 9, "apic"}, {1, 'd', 11, "sep"}, {1, 'd', 12, "mtrr"},
+	{1, 'd', 13, "pge"}, {1, 'd', 14, "mca"}, {1, 'd', 15, "cmov"}, {1, 'd', 16, "pat"},
+	{1, 'd', 17, "pse36"}, {1, 'd', 19, "clflush"}, {1, 'd', 21, "dts"}, {1, 'd', 22, "acpi"},
+	{1, 'd', 23, "mmx"}, {1, 'd', 24, "fxsr"}, {1, 'd', 25, "sse"}, {1, 'd', 26, "sse2"},
+	{1, 'd', 27, "ss"}, {1, 'd', 28, "ht"}, {1, 'd', 29, "tm"}, {1, 'd', 31, "pbe"},
+
+	// Hoja 1, ECX
+	{1, 'c', 0, "pni"}, {1, 'c', 1, "pclmulqdq"}, {1, 'c', 2, "dtes64"}, {1, 'c', 3, "monitor"},
+	{1, 'c', 4, "ds_cpl"}, {1, 'c', 5, "vmx"}, {1, 'c', 6, "smx"}, {1, 'c', 7, "est"},
+	{1, 'c', 8, "tm2"}, {1, 'c', 9, "ssse3"}, {1, 'c', 10, "cnxt_id"}, {1, 'c', 11, "sdbg"},
+	{1, 'c', 12, "fma"}, {1, 'c', 13, "cx16"}, {1, 'c', 14, "xtpr"}, {1, 'c', 15, "pdcm"},
+	{1, 'c', 17, "pcid"}, {1, 'c', 18, "dca"}, {1, 'c', 19, "sse4_1"}, {1, 'c', 20, "sse4_2"},
+	{1, 'c', 21, "x2apic"}, {1, 'c', 22, "movbe"}, {1, 'c', 23, "popcnt"}, {1, 'c', 24, "tsc_deadline_timer"},
+	{1, 'c', 25, "aes"}, {1, 'c', 26, "xsave"}, {1, 'c', 28, "avx"}, {1, 'c', 29, "f16c"},
+	{1, 'c', 30, "rdrand"}, {1, 'c', 31, "hypervisor"},
+
+	// Hoja 7, EBX
+	{7, 'b', 0, "fsgsbase"}, {7, 'b', 1, "tsc_adjust"}, {7, 'b', 2, "sgx"}, {7, 'b', 3, "bmi1"},
+	{7, 'b', 4, "hle"}, {7, 'b', 5, "avx2"}, {7, 'b', 7, "smep"}, {7, 'b', 8, "bmi2"},
+	{7, 'b', 9, "erms"}, {7, 'b', 10, "invpcid"}, {7, 'b', 11, "rtm"}, {7, 'b', 16, "avx512f"},
+	{7, 'b', 17, "avx512dq"}, {7, 'b', 18, "rdseed"}, {7, 'b', 19, "adx"}, {7, 'b', 20, "smap"},
+	{7, 'b', 21, "avx512ifma"}, {7, 'b', 23, "clflushopt"}, {7, 'b', 24, "clwb"}, {7, 'b', 25, "intel_pt"},
+	{7, 'b', 26, "avx512pf"}, {7, 'b', 27, "avx512er"}, {7, 'b', 28, "avx512cd"}, {7, 'b', 29, "sha_ni"},
+	{7, 'b', 30, "avx512bw"}, {7, 'b', 31, "avx512vl"},
+
+	// Hoja 7, ECX
+	{7, 'c', 1, "avx512vbmi"}, {7, 'c', 2, "umip"}, {7, 'c', 3, "pku"}, {7, 'c', 4, "ospke"},
+	{7, 'c', 5, "waitpkg"}, {7, 'c', 6, "avx512_vbmi2"}, {7, 'c', 8, "gfni"}, {7, 'c', 9, "vaes"},
+	{7, 'c', 10, "vpclmulqdq"}, {7, 'c', 11, "avx512_vnni"}, {7, 'c', 12, "avx512_bitalg"},
+	{7, 'c', 14, "avx512_vpopcntdq"}, {7, 'c', 16, "la57"}, {7, 'c', 22, "rdpid"},
+	{7, 'c', 25, "cldemote"}, {7, 'c', 27, "movdiri"}, {7, 'c', 28, "movdir64b"},
+
+	// Hoja 7, EDX
+	{7, 'd', 2, "avx512_4vnniw"}, {7, 'd', 3, "avx512_4fmaps"}, {7, 'd', 4, "fsrm"},
+	{7, 'd', 8, "avx512_vp2intersect"}, {7, 'd', 10, "md_clear"}, {7, 'd', 14, "serialize"},
+	{7, 'd', 16, "tsxldtrk"}, {7, 'd', 18, "pconfig"}, {7, 'd', 20, "ibt"}, {7, 'd', 22, "amx_bf16"},
+	{7, 'd', 23, "avx512_fp16"}, {7, 'd', 24, "amx_tile"}, {7, 'd', 25, "amx_int8"},
+	{7, 'd', 28, "flush_l1d"}, {7, 'd', 29, "arch_capabilities"},
+
+	// Hoja 0x80000001, EDX y ECX
+	{0x80000001, 'd', 11, "syscall"}, {0x80000001, 'd', 20, "nx"}, {0x80000001, 'd', 22, "mmxext"},
+	{0x80000001, 'd', 26, "pdpe1gb"}, {0x80000001, 'd', 27, "rdtscp"}, {0x80000001, 'd', 29, "lm"},
+	{0x80000001, 'd', 30, "3dnowext"}, {0x80000001, 'd', 31, "3dnow"},
+	{0x80000001, 'c', 0, "lahf_lm"}, {0x80000001, 'c', 1, "cmp_legacy"}, {0x80000001, 'c', 2, "svm"},
+	{0x80000001, 'c', 3, "extapic"}, {0x80000001, 'c', 4, "cr8_legacy"}, {0x80000001, 'c', 5, "abm"},
+	{0x80000001, 'c', 6, "sse4a"}, {0x80000001, 'c', 7, "misalignsse"}, {0x80000001, 'c', 8, "3dnowprefetch"},
+	{0x80000001, 'c', 9, "osvw"}, {0x80000001, 'c', 10, "ibs"}, {0x80000001, 'c', 11, "xop"},
+	{0x80000001, 'c', 12, "skinit"}, {0x80000001, 'c', 13, "wdt"}, {0x80000001, 'c', 15, "lwp"},
+	{0x80000001, 'c', 16, "fma4"}, {0x80000001, 'c', 17, "tce"}, {0x80000001, 'c', 21, "tbm"},
+	{0x80000001, 'c', 22, "topoext"}, {0x80000001, 'c', 23, "perfctr_core"}, {0x80000001, 'c', 24, "perfctr_nb"},
+	{0x80000001, 'c', 26, "bpext"}, {0x80000001, 'c', 27, "ptsc"}, {0x80000001, 'c', 28, "perfctr_llc"},
+	{0x80000001, 'c', 29, "mwaitx"},
+}
+
+// hypervisorVendors relaciona la firma de la hoja 0x40000000 con el
+// hipervisor
+var hypervisorVendors = map[string]string{
+	"KVMKVMKVM":    "KVM",
+	"Linux KVM Hv": "KVM (Hyper-V enlightenments)",
+	"TCGTCGTCGTCG": "QEMU (TCG)",
+	"VMwareVMware": "VMware",
+	"Microsoft Hv": "Hyper-V",
+	"XenVMMXenVMM": "Xen",
+	"VBoxVBoxVBox": "VirtualBox",
+	"bhyve bhyve":  "bhyve",
+	"ACRNACRNACRN": "ACRN",
+	"QNXQVMBSQG":   "QNX",
+	"prl hyperv":   "Parallels",
+	"lrpepyh  vr":  "Parallels",
+}
+
+// readCPUID ejecuta CPUID en el procesador actual. Devuelve nil fuera de
+// amd64 o si se escanea otra raíz (CPUID describe la máquina que ejecuta
+// hwscan, no la capturada).
+func (d *Detector) readCPUID() *CPUIDInfo {
+	if !cpuidAvailable || !d.live() {
+		return nil
+	}
+	return decodeCPUID(cpuid)
+}
+
+// decodeCPUID decodifica fabricante, marca, familia/modelo/stepping,
+// características, cachés e hipervisor a partir de una función CPUID
+func decodeCPUID(fn cpuidFunc) *CPUIDInfo {
+	info := &CPUIDInfo{
+		Features: make([]string, 0),
+		Caches:   make([]CPUCache, 0),
+	}
+
+	maxLeaf, ebx, ecx, edx := fn(0, 0)
+	info.Vendor = registerString(ebx, edx, ecx)
+	maxExt, _, _, _ := fn(0x80000000, 0)
+	if maxExt < 0x80000000 {
+		maxExt = 0
+	}
+	info.MaxLeaf, info.MaxExtLeaf = maxLeaf, maxExt
+
+	if maxLeaf >= 1 {
+		eax, _, _, _ := fn(1, 0)
+		family := (eax >> 8) & 0xF
+		model := (eax >> 4) & 0xF
+		if family == 0xF {
+			family += (eax >> 20) & 0xFF
+		}
+		if family == 0x6 || family >= 0xF {
+			model |= ((eax >> 16) & 0xF) << 4
+		}
+		info.Family, info.Model, info.Stepping = int(family), int(model), int(eax&0xF)
+	}
+
+	if maxExt >= 0x80000004 {
+		brand := make([]byte, 0, 48)
+		for leaf := uint32(0x80000002); leaf <= 0x80000004; leaf++ {
+			a, b, c, d := fn(leaf, 0)
+			for _, r := range []uint32{a, b, c, d} {
+				brand = binary.LittleEndian.AppendUint32(brand, r)
+			}
+		}
+		info.Brand = strings.Join(strings.Fields(trimNul(brand)), " ")
+	}
+
+	regs := make(map[uint32][4]uint32)
+	for _, leaf := range []uint32{1, 7, 0x80000001} {
+		if (leaf < 0x80000000 && leaf <= maxLeaf) || (leaf >= 0x80000000 && leaf <= maxExt) {
+			a, b, c, d := fn(leaf, 0)
+			regs[leaf] = [4]uint32{a, b, c, d}
+		}
+	}
+	for _, f := range cpuidFeatures {
+		r, ok := regs[f.leaf]
+		if !ok {
+			continue
+		}
+		if r[cpuidRegister(f.reg)]&(1<<f.bit) != 0 {
+			info.Features = append(info.Features, f.name)
+		}
+	}
+
+	info.Caches = cpuidCaches(fn, info.Vendor, maxLeaf, maxExt, regs[0x80000001][2]&(1<<22) != 0)
+
+	if regs[1][2]&(1<<31) != 0 {
+		_, b, c, d := fn(0x40000000, 0)
+		info.HypervisorVendor = trimNul([]byte(registerString(b, c, d)))
+		info.Hypervisor = hypervisorVendors[info.HypervisorVendor]
+		if info.Hypervisor == "" {
+			info.Hypervisor = HypervisorUnknown
+		}
+	}
+	return info
+}
+
+// cpuidCaches recorre las hojas de parámetros de caché deterministas: la 4
+// en Intel (y VIA/Zhaoxin) y la 0x8000001D en AMD con topoext. Los AMD antiguos solo tienen
+// los descriptores de 0x80000005/0x80000006.
+func cpuidCaches(fn cpuidFunc, vendor string, maxLeaf, maxExt uint32, topoext bool) []CPUCache {
+	caches := make([]CPUCache, 0)
+
+	leaf := uint32(0)
+	switch {
+	case vendor != "AuthenticAMD" && vendor != "HygonGenuine" && maxLeaf >= 4:
+		leaf = 4
+	case topoext && maxExt >= 0x8000001D:
+		leaf = 0x8000001D
+	}
+
+	if leaf != 0 {
+		for sub := uint32(0); sub < 16; sub++ {
+			a, b, c, _ := fn(leaf, sub)
+			typ := a & 0x1F
+			if typ == 0 {
+				break
+			}
+			level := int((a >> 5) & 0x7)
+			ways := (b>>22)&0x3FF + 1
+			partitions := (b>>12)&0x3FF + 1
+			line := b&0xFFF + 1
+			sets := c + 1
+			name := map[uint32]string{1: "Data", 2: "Instruction", 3: "Unified"}[typ]
+			caches = append(caches, CPUCache{
+				Name:      cacheName(level, name),
+				Level:     level,
+				Type:      name,
+				SizeBytes: uint64(ways) * uint64(partitions) * uint64(line) * uint64(sets),
+				Ways:      int(ways),
+				LineSize:  int(line),
+				SharedBy:  int((a>>14)&0xFFF + 1),
+			})
+		}
+	} else if maxExt >= 0x80000006 {
+		_, _, c5, d5 := fn(0x80000005, 0)
+		_, _, c6, d6 := fn(0x80000006, 0)
+		legacy := []CPUCache{
+			{Name: "L1d", Level: 1, Type: "Data", SizeBytes: uint64(c5>>24) << 10, LineSize: int(c5 & 0xFF)},
+			{Name: "L1i", Level: 1, Type: "Instruction", SizeBytes: uint64(d5>>24) << 10, LineSize: int(d5 & 0xFF)},
+			{Name: "L2", Level: 2, Type: "Unified", SizeBytes: uint64(c6>>16) << 10, LineSize: int(c6 & 0xFF)},
+			{Name: "L3", Level: 3, Type: "Unified", SizeBytes: uint64(d6>>18) << 19, LineSize: int(d6 & 0xFF)},
+		}
+		for _, c := range legacy {
+			if c.SizeBytes > 0 {
+				caches = append(caches, c)
+			}
+		}
+	}
+
+	sort.SliceStable(caches, func(i, j int) bool { return caches[i].Name < caches[j].Name })
+	return caches
+}
+
+// crossCheckCPUID compara CPUID con lo leído de /proc/cpuinfo: completa los
+// campos que faltan y anota las diferencias. Las características que CPUID
+// informa y el kernel no publica suelen estar desactivadas por el kernel o
+// el microcódigo (ej: TSX con tsx=off).
+func crossCheckCPUID(cpu *CPUInfo, id *CPUIDInfo) {
+	id.Mismatches = make([]string, 0)
+	id.HiddenFeatures = make([]string, 0)
+
+	check := func(field, proc, native string) {
+		if proc != "" && native != "" && proc != native {
+			id.Mismatches = append(id.Mismatches, fmt.Sprintf("%s: /proc/cpuinfo %q, CPUID %q", field, proc, native))
+		}
+	}
+	check("fabricante", cpu.Vendor, id.Vendor)
+	check("modelo", strings.Join(strings.Fields(cpu.Model), " "), id.Brand)
+	if cpu.Family > 0 {
+		check("familia/modelo/stepping",
+			fmt.Sprintf("%d/%d/%d", cpu.Family, cpu.ModelNumber, cpu.Stepping),
+			fmt.Sprintf("%d/%d/%d", id.Family, id.Model, id.Stepping))
+	}
+
+	if cpu.Vendor == "" {
+		cpu.Vendor = id.Vendor
+	}
+	if cpu.Model == "" {
+		cpu.Model = id.Brand
+	}
+	if cpu.Family == 0 {
+		cpu.Family, cpu.ModelNumber, cpu.Stepping = id.Family, id.Model, id.Stepping
+	}
+
+	if len(cpu.Flags) == 0 {
+		cpu.Flags = append(cpu.Flags, id.Features...)
+		return
+	}
+	flags := make(map[string]bool, len(cpu.Flags))
+	for _, f := range cpu.Flags {
+		flags[f] = true
+	}
+	for _, f := range id.Features {
+		if !flags[f] {
+			id.HiddenFeatures = append(id.HiddenFeatures, f)
+		}
+	}
+}
+
+// cpuidRegister devuelve la posición de un registro ('b', 'c', 'd') en el
+// resultado [EAX, EBX, ECX, EDX] de CPUID
+func cpuidRegister(reg byte) int {
+	return int(reg-'b') + 1
+}
+
+// registerString concatena registros de CPUID como texto ASCII (fabricante,
+// firma del hipervisor)
+func registerString(regs ...uint32) string {
+	b := make([]byte, 0, 4*len(regs))
+	for _, r := range regs {
+		b = binary.LittleEndian.AppendUint32(b, r)
+	}
+	return string(b)
+}
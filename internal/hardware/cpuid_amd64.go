@@ -0,0 +1,8 @@
+package hardware
+
+// cpuidAvailable indica si la arquitectura permite ejecutar CPUID
+const cpuidAvailable = true
+
+// cpuid ejecuta la instrucción CPUID con EAX=leaf y ECX=subleaf
+// (implementada en cpuid_amd64.s)
+func cpuid(leaf, subleaf uint32) (eax, ebx, ecx, edx uint32)
@@ -0,0 +1,12 @@
+//go:build !amd64
+
+package hardware
+
+// cpuidAvailable indica si la arquitectura permite ejecutar CPUID. En arm64
+// y armv7 no existe: la información sale solo de /proc/cpuinfo.
+const cpuidAvailable = false
+
+// cpuid no hace nada fuera de amd64
+func cpuid(leaf, subleaf uint32) (eax, ebx, ecx, edx uint32) {
+	return 0, 0, 0, 0
+}
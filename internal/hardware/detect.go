@@ -0,0 +1,303 @@
+package hardware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+)
+
+// timeoutGrace es el margen que se da a un detector tras vencer su plazo para
+// que termine con lo que tenga (sus comandos externos ya fueron terminados)
+const timeoutGrace = 500 * time.Millisecond
+
+// DetectOptions configura la ejecución de DetectContext
+type DetectOptions struct {
+	// Timeout es el plazo máximo de cada detector (0 = sin límite)
+	Timeout time.Duration
+	// Timeouts sobrescribe el plazo de detectores concretos por nombre
+	// (ej: "gpu": 30 * time.Second)
+	Timeouts map[string]time.Duration
+}
+
+// timeoutFor devuelve el plazo configurado para un detector
+func (o DetectOptions) timeoutFor(name string) time.Duration {
+	if t, ok := o.Timeouts[name]; ok {
+		return t
+	}
+	return o.Timeout
+}
+
+// detectorSpec describe uno de los detectores que ejecuta DetectContext.
+// run devuelve una función que guarda el resultado en HardwareInfo; la aplica
+// el planificador, nunca el detector, para que un detector abandonado por
+// timeout no escriba en el resultado. view es una copia de HardwareInfo con
+// los resultados de deps (nil si no tiene dependencias).
+type detectorSpec struct {
+	name     string   // Nombre usado en DetectOptions.Timeouts y en HardwareInfo.TimedOut
+	label    string   // Nombre legible para los mensajes
+	source   string   // Fuente por defecto en Diagnostics (el detector puede cambiarla)
+	critical bool     // Si falla, la detección completa falla
+	deps     []string // Detectores que deben terminar antes de ejecutar este
+	run      func(ctx context.Context, rep *report, view *HardwareInfo) (func(*HardwareInfo), error)
+}
+
+// detectorResult es el resultado de ejecutar un detectorSpec
+type detectorResult struct {
+	apply    func(*HardwareInfo)
+	err      error
+	timedOut bool
+}
+
+// DetectorNames devuelve los nombres de los detectores integrados y de los
+// plugins registrados, en el orden en que se presentan sus resultados
+func DetectorNames() []string {
+	specs := (&Detector{}).detectors()
+	names := make([]string, len(specs))
+	for i, spec := range specs {
+		names[i] = spec.name
+	}
+	return names
+}
+
+// detectors devuelve los detectores integrados seguidos de los plugins
+// registrados
+func (d *Detector) detectors() []detectorSpec {
+	specs := d.builtinDetectors()
+	for _, p := range Plugins() {
+		specs = append(specs, d.pluginSpec(p))
+	}
+	return specs
+}
+
+// builtinDetectors devuelve la lista de detectores integrados
+func (d *Detector) builtinDetectors() []detectorSpec {
+	return []detectorSpec{
+		{name: "cpu", label: "CPU", source: "procfs", critical: true, run: func(ctx context.Context, rep *report, _ *HardwareInfo) (func(*HardwareInfo), error) {
+			cpu, err := d.detectCPU(rep)
+			return func(info *HardwareInfo) { info.CPU = cpu }, err
+		}},
+		{name: "memory", label: "memoria", source: "procfs", critical: true, run: func(ctx context.Context, rep *report, _ *HardwareInfo) (func(*HardwareInfo), error) {
+			mem, err := d.detectMemory(ctx, rep)
+			return func(info *HardwareInfo) { info.Memory = mem }, err
+		}},
+		{name: "motherboard", label: "placa madre", source: "sysfs", run: func(ctx context.Context, rep *report, _ *HardwareInfo) (func(*HardwareInfo), error) {
+			mb, err := d.detectMotherboard(rep)
+			return func(info *HardwareInfo) { info.Motherboard = mb }, err
+		}},
+		{name: "system", label: "sistema", source: "sysfs", run: func(ctx context.Context, rep *report, _ *HardwareInfo) (func(*HardwareInfo), error) {
+			sys, err := d.detectSystem(rep)
+			return func(info *HardwareInfo) { info.System = sys }, err
+		}},
+		{name: "firmware", label: "firmware y arranque", source: "efivarfs", run: func(ctx context.Context, rep *report, _ *HardwareInfo) (func(*HardwareInfo), error) {
+			fw, err := d.detectFirmware(rep)
+			return func(info *HardwareInfo) { info.Firmware = fw }, err
+		}},
+		{name: "tpm", label: "TPM", source: "sysfs", run: func(ctx context.Context, rep *report, _ *HardwareInfo) (func(*HardwareInfo), error) {
+			tpm, err := d.detectTPM(rep)
+			return func(info *HardwareInfo) { info.TPM = tpm }, err
+		}},
+		{name: "platform", label: "plataforma", source: "sysfs", deps: []string{"cpu"}, run: func(ctx context.Context, rep *report, view *HardwareInfo) (func(*HardwareInfo), error) {
+			platform := d.detectPlatform(view.CPU)
+			return func(info *HardwareInfo) { info.Platform = platform }, nil
+		}},
+		{name: "pci", label: "dispositivos PCI", source: "sysfs", run: func(ctx context.Context, rep *report, _ *HardwareInfo) (func(*HardwareInfo), error) {
+			pci, err := d.pciDevices()
+			return func(info *HardwareInfo) { info.PCI = pci }, err
+		}},
+		{name: "gpu", label: "GPU", source: "sysfs", run: func(ctx context.Context, rep *report, _ *HardwareInfo) (func(*HardwareInfo), error) {
+			gpus, err := d.detectGPU(ctx, rep)
+			return func(info *HardwareInfo) { info.GPU = gpus }, err
+		}},
+		{name: "displays", label: "monitores", source: "sysfs", run: func(ctx context.Context, rep *report, _ *HardwareInfo) (func(*HardwareInfo), error) {
+			displays, err := d.detectDisplays(rep)
+			return func(info *HardwareInfo) { info.Displays = displays }, err
+		}},
+		{name: "disks", label: "discos", source: "sysfs", run: func(ctx context.Context, rep *report, _ *HardwareInfo) (func(*HardwareInfo), error) {
+			disks, err := d.detectDisks(ctx, rep)
+			return func(info *HardwareInfo) { info.Disks = disks }, err
+		}},
+		{name: "storage", label: "topología de almacenamiento", source: "sysfs", run: func(ctx context.Context, rep *report, _ *HardwareInfo) (func(*HardwareInfo), error) {
+			topo, err := d.detectStorage()
+			return func(info *HardwareInfo) { info.Storage = topo }, err
+		}},
+		{name: "network", label: "red", source: "sysfs", run: func(ctx context.Context, rep *report, _ *HardwareInfo) (func(*HardwareInfo), error) {
+			ifaces, err := d.detectNetwork(rep)
+			return func(info *HardwareInfo) { info.Network = ifaces }, err
+		}},
+		{name: "usb", label: "USB", source: "sysfs", run: func(ctx context.Context, rep *report, _ *HardwareInfo) (func(*HardwareInfo), error) {
+			usb, err := d.detectUSB()
+			return func(info *HardwareInfo) { info.USB = usb }, err
+		}},
+		{name: "power", label: "energía", source: "sysfs", run: func(ctx context.Context, rep *report, _ *HardwareInfo) (func(*HardwareInfo), error) {
+			power, err := d.detectPower(rep)
+			return func(info *HardwareInfo) { info.Power = power }, err
+		}},
+		{name: "sensors", label: "sensores", source: "sysfs", run: func(ctx context.Context, rep *report, _ *HardwareInfo) (func(*HardwareInfo), error) {
+			chips, err := d.detectSensors(rep)
+			return func(info *HardwareInfo) { info.Sensors = chips }, err
+		}},
+	}
+}
+
+// Detect realiza la detección completa del hardware del sistema
+func Detect() (*HardwareInfo, error) {
+	return NewDetector("/").Detect()
+}
+
+// DetectContext realiza la detección completa del hardware del sistema con
+// detectores concurrentes. Ver Detector.DetectContext.
+func DetectContext(ctx context.Context, opts DetectOptions) (*HardwareInfo, error) {
+	return NewDetector("/").DetectContext(ctx, opts)
+}
+
+// Detect realiza la detección completa del hardware bajo la raíz del detector,
+// sin límite de tiempo por detector
+func (d *Detector) Detect() (*HardwareInfo, error) {
+	return d.DetectContext(context.Background(), DetectOptions{})
+}
+
+// DetectContext ejecuta todos los detectores en paralelo; los que declaran
+// dependencias esperan a que estas terminen. Cada uno tiene su propio plazo
+// según opts; al vencer se terminan sus comandos externos y se registra su
+// nombre en HardwareInfo.TimedOut. Solo los fallos de CPU y memoria son
+// fatales; el resto queda registrado en HardwareInfo.Diagnostics.
+func (d *Detector) DetectContext(ctx context.Context, opts DetectOptions) (*HardwareInfo, error) {
+	info := &HardwareInfo{
+		Timestamp:    time.Now().Format(time.RFC3339),
+		TimedOut:     make([]string, 0),
+		Platform:     PlatformInfo{Evidence: make([]string, 0)},
+		Firmware:     FirmwareInfo{BootOrder: make([]string, 0), BootEntries: make([]BootEntry, 0)},
+		TPM:          TPMInfo{Banks: make([]string, 0), PCRs: make([]TPMPCR, 0)},
+		SensorSeries: make([]SensorTrend, 0),
+		Sections:     make(map[string]Section),
+		Diagnostics:  make([]DetectorDiagnostic, 0),
+	}
+
+	specs := d.detectors()
+	blocked := unresolvable(specs)
+	results := make([]detectorResult, len(specs))
+	reports := make([]*report, len(specs))
+	elapsed := make([]time.Duration, len(specs))
+	done := make(map[string]chan struct{}, len(specs))
+	index := make(map[string]int, len(specs))
+	for i, spec := range specs {
+		reports[i] = &report{source: spec.source}
+		done[spec.name] = make(chan struct{})
+		index[spec.name] = i
+	}
+
+	// Los resultados se aplican a medida que terminan los detectores para que
+	// sus dependientes los vean; mu protege info mientras tanto
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i, spec := range specs {
+		wg.Add(1)
+		go func(i int, spec detectorSpec) {
+			defer wg.Done()
+			defer close(done[spec.name])
+
+			if reason, ok := blocked[spec.name]; ok {
+				results[i] = detectorResult{err: errors.New(reason)}
+				return
+			}
+
+			var view *HardwareInfo
+			if len(spec.deps) > 0 {
+				for _, dep := range spec.deps {
+					<-done[dep]
+					// Sin resultado: falló, se omitió o no devolvió datos
+					if res := results[index[dep]]; res.err != nil || res.apply == nil {
+						reports[i].skip("dependencia %s no disponible", dep)
+						return
+					}
+				}
+				mu.Lock()
+				snapshot := *info
+				snapshot.Sections = maps.Clone(info.Sections)
+				mu.Unlock()
+				view = &snapshot
+			}
+
+			start := time.Now()
+			res := runDetector(ctx, spec, reports[i], view, opts.timeoutFor(spec.name))
+			elapsed[i] = time.Since(start)
+
+			if res.apply != nil {
+				mu.Lock()
+				res.apply(info)
+				mu.Unlock()
+			}
+			results[i] = res
+		}(i, spec)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Recorrer en orden para que el diagnóstico sea determinista
+	for i, spec := range specs {
+		res := results[i]
+		if res.timedOut {
+			info.TimedOut = append(info.TimedOut, spec.name)
+		}
+		if res.err != nil && spec.critical {
+			return nil, fmt.Errorf("error detectando %s: %w", spec.label, res.err)
+		}
+
+		info.Diagnostics = append(info.Diagnostics, reports[i].diagnostic(spec.name, res, elapsed[i]))
+	}
+
+	// Generar Machine ID (debe ser al final para tener toda la info disponible)
+	info.MachineID = d.GenerateMachineID(info)
+
+	return info, nil
+}
+
+// runDetector ejecuta un detector con su propio plazo. Si el plazo vence, se
+// cancela su contexto (terminando sus comandos externos) y se espera un margen
+// breve a que devuelva un resultado parcial antes de abandonarlo. Un pánico
+// del detector se devuelve como error.
+func runDetector(parent context.Context, spec detectorSpec, rep *report, view *HardwareInfo, timeout time.Duration) detectorResult {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(parent, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(parent)
+	}
+	defer cancel()
+
+	done := make(chan detectorResult, 1)
+	go func() {
+		// Un pánico (típicamente de un plugin) falla solo este detector
+		defer func() {
+			if r := recover(); r != nil {
+				done <- detectorResult{err: fmt.Errorf("pánico: %v", r)}
+			}
+		}()
+		apply, err := spec.run(ctx, rep, view)
+		done <- detectorResult{apply: apply, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		res.timedOut = errors.Is(ctx.Err(), context.DeadlineExceeded)
+		return res
+	case <-ctx.Done():
+	}
+
+	select {
+	case res := <-done:
+		res.timedOut = true
+		return res
+	case <-time.After(timeoutGrace):
+		return detectorResult{err: ctx.Err(), timedOut: true}
+	}
+}
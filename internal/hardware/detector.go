@@ -2,68 +2,102 @@ package hardware
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
-	"time"
-)
+	"sync"
 
-// Detect realiza la detección completa del hardware del sistema
-func Detect() (*HardwareInfo, error) {
-	info := &HardwareInfo{
-		Timestamp: time.Now().Format(time.RFC3339),
-	}
+	"github.com/Lexharden/hwscan/internal/ids"
+)
 
-	var err error
+// Detector detecta el hardware leyendo /proc y /sys bajo un directorio raíz
+// configurable. Con una raíz distinta de "/" se puede escanear una copia de
+// /proc y /sys capturada en otra máquina.
+//
+// Los comandos externos (dmidecode, lspci, nvidia-smi) se ejecutan a través de
+// Runner, que puede reemplazarse por un ReplayRunner para reproducir salidas
+// grabadas con "hwscan capture".
+type Detector struct {
+	Root   string        // Directorio raíz del sistema de archivos ("/" por defecto)
+	Runner CommandRunner // Ejecutor de comandos externos (ExecRunner por defecto)
+	PCIIDs *ids.Database // Base de datos pci.ids (la del sistema o la embebida por defecto)
+	USBIDs *ids.Database // Base de datos usb.ids (la del sistema o la embebida por defecto)
+
+	// Abre el dispositivo TPM 2.0 para consultar fabricante y estado. Si es
+	// nil se usa /dev/tpmrm0, solo escaneando el sistema en ejecución; puede
+	// reemplazarse por un simulador que responda a los comandos TPM 2.0.
+	OpenTPM func() (io.ReadWriteCloser, error)
+
+	// Salud mínima de batería en % (DefaultBatteryHealthThreshold si es 0)
+	BatteryHealthThreshold float64
+
+	// Tabla SMBIOS leída una sola vez y compartida por los detectores
+	smbiosOnce sync.Once
+	smbios     *smbiosTable
+	smbiosErr  error
+
+	// Dispositivos PCI enumerados una sola vez (sección PCI y GPUs)
+	pciOnce sync.Once
+	pci     []PCIDevice
+	pciErr  error
+}
 
-	// Detectar CPU
-	info.CPU, err = detectCPU()
-	if err != nil {
-		return nil, fmt.Errorf("error detectando CPU: %w", err)
+// NewDetector crea un detector que lee el sistema de archivos bajo root.
+// Una raíz vacía equivale a "/".
+func NewDetector(root string) *Detector {
+	if root == "" {
+		root = "/"
 	}
+	return &Detector{Root: root, Runner: ExecRunner{}}
+}
 
-	// Detectar Memoria
-	info.Memory, err = detectMemory()
-	if err != nil {
-		return nil, fmt.Errorf("error detectando memoria: %w", err)
-	}
+// path traduce una ruta absoluta del sistema (ej: /proc/cpuinfo) a la ruta
+// equivalente bajo el directorio raíz del detector.
+func (d *Detector) path(p string) string {
+	return filepath.Join(d.Root, p)
+}
 
-	// Detectar Placa Madre
-	info.Motherboard, err = detectMotherboard()
-	if err != nil {
-		// No es crítico, continuamos
-		fmt.Printf("Advertencia: error detectando placa madre: %v\n", err)
-	}
+// live indica si el detector escanea el sistema en ejecución. Las consultas
+// que no pasan por el sistema de archivos (ioctl) solo tienen sentido en ese
+// caso.
+func (d *Detector) live() bool {
+	return filepath.Clean(d.Root) == "/"
+}
 
-	// Detectar GPU
-	info.GPU, err = detectGPU()
-	if err != nil {
-		// No es crítico, continuamos
-		fmt.Printf("Advertencia: error detectando GPU: %v\n", err)
+// run ejecuta un comando externo a través del Runner del detector. El
+// comando se termina si ctx se cancela o vence.
+func (d *Detector) run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	if d.Runner == nil {
+		return ExecRunner{}.Run(ctx, name, args...)
 	}
+	return d.Runner.Run(ctx, name, args...)
+}
 
-	// Detectar Discos
-	info.Disks, err = detectDisks()
+// readString lee un archivo bajo la raíz y devuelve su contenido sin espacios
+// al inicio ni al final.
+func (d *Detector) readString(p string) (string, error) {
+	data, err := os.ReadFile(d.path(p))
 	if err != nil {
-		fmt.Printf("Advertencia: error detectando discos: %v\n", err)
+		return "", err
 	}
-
-	// Generar Machine ID (debe ser al final para tener toda la info disponible)
-	info.MachineID = GenerateMachineID(info)
-
-	return info, nil
+	return strings.TrimSpace(string(data)), nil
 }
 
 // detectCPU lee información del procesador desde /proc/cpuinfo
-func detectCPU() (CPUInfo, error) {
+func (d *Detector) detectCPU(rep *report) (CPUInfo, error) {
 	cpu := CPUInfo{
-		Flags: make([]string, 0),
+		Flags:     make([]string, 0),
+		Bugs:      make([]string, 0),
+		CoreList:  make([]CPUCore, 0),
+		Caches:    make([]CPUCache, 0),
+		NUMANodes: make([]NUMANode, 0),
 	}
 
-	file, err := os.Open("/proc/cpuinfo")
+	file, err := os.Open(d.path("/proc/cpuinfo"))
 	if err != nil {
 		return cpu, err
 	}
@@ -72,6 +106,7 @@ func detectCPU() (CPUInfo, error) {
 	scanner := bufio.NewScanner(file)
 	processorCount := 0
 	coresMap := make(map[string]bool)
+	physicalID := ""
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -103,12 +138,35 @@ func detectCPU() (CPUInfo, error) {
 			if cpu.CacheSize == "" {
 				cpu.CacheSize = value
 			}
+		case "physical id":
+			physicalID = value
 		case "core id":
-			coresMap[value] = true
+			// core id se repite en cada socket
+			coresMap[physicalID+"/"+value] = true
 		case "flags":
 			if len(cpu.Flags) == 0 {
 				cpu.Flags = strings.Fields(value)
 			}
+		case "bugs":
+			if len(cpu.Bugs) == 0 {
+				cpu.Bugs = strings.Fields(value)
+			}
+		case "cpu family":
+			if cpu.Family == 0 {
+				cpu.Family, _ = strconv.Atoi(value)
+			}
+		case "model":
+			if cpu.ModelNumber == 0 {
+				cpu.ModelNumber, _ = strconv.Atoi(value)
+			}
+		case "stepping":
+			if cpu.Stepping == 0 {
+				cpu.Stepping, _ = strconv.Atoi(value)
+			}
+		case "microcode":
+			if cpu.Microcode == "" {
+				cpu.Microcode = value
+			}
 		}
 	}
 
@@ -119,16 +177,70 @@ func detectCPU() (CPUInfo, error) {
 	}
 
 	// Intentar obtener la velocidad máxima del CPU
-	maxSpeed := getMaxCPUFrequency()
+	maxSpeed := d.getMaxCPUFrequency()
+
+	// La topología de sysfs es más fiable que /proc/cpuinfo (CPU
+	// desconectadas, núcleos híbridos) y da la frecuencia de cada núcleo
+	if d.readCPUTopology(&cpu) {
+		for _, core := range cpu.CoreList {
+			maxSpeed = max(maxSpeed, core.MaxMHz)
+		}
+	} else {
+		rep.note("sin topología en /sys/devices/system/cpu: núcleos contados desde /proc/cpuinfo")
+	}
 	if maxSpeed > 0 {
 		cpu.Speed = maxSpeed
 	}
+	// CPUID nativo: contrasta /proc/cpuinfo y completa lo que falte
+	if id := d.readCPUID(); id != nil {
+		crossCheckCPUID(&cpu, id)
+		for _, m := range id.Mismatches {
+			rep.note("CPUID difiere de /proc/cpuinfo en %s", m)
+		}
+		cpu.CPUID = id
+	}
+
+	cpu.Vulnerabilities = d.readCPUVulnerabilities()
+	if len(cpu.Vulnerabilities) == 0 {
+		rep.note("el kernel no publica /sys/devices/system/cpu/vulnerabilities")
+	}
+	for _, v := range cpu.Vulnerabilities {
+		if v.Name == "old_microcode" && v.Status == VulnVulnerable {
+			cpu.OldMicrocode = true
+		}
+	}
+
+	if cpu.CacheSize == "" && len(cpu.Caches) > 0 {
+		// arm64 no publica "cache size": usar el último nivel
+		cpu.CacheSize = formatCacheSize(cpu.Caches[len(cpu.Caches)-1].SizeBytes)
+	}
+
+	// Completar con SMBIOS tipo 4 lo que /proc/cpuinfo no expone
+	// (ej: arm64 no tiene "model name" y las VMs no tienen cpufreq)
+	if table, err := d.readSMBIOS(); err == nil {
+		for _, p := range table.Processors {
+			if !p.Populated {
+				continue
+			}
+			if cpu.Model == "" && p.Version != "" {
+				cpu.Model = p.Version
+				rep.note("modelo obtenido de SMBIOS (tipo 4)")
+			}
+			if cpu.Vendor == "" {
+				cpu.Vendor = p.Manufacturer
+			}
+			if maxSpeed == 0 && p.MaxSpeedMHz > 0 {
+				cpu.Speed = float64(p.MaxSpeedMHz)
+			}
+			break
+		}
+	}
 
 	return cpu, scanner.Err()
 }
 
 // getMaxCPUFrequency intenta obtener la velocidad máxima del CPU en MHz
-func getMaxCPUFrequency() float64 {
+func (d *Detector) getMaxCPUFrequency() float64 {
 	// Intentar leer desde cpufreq (velocidad máxima del CPU)
 	paths := []string{
 		"/sys/devices/system/cpu/cpu0/cpufreq/cpuinfo_max_freq",
@@ -136,7 +248,7 @@ func getMaxCPUFrequency() float64 {
 	}
 
 	for _, path := range paths {
-		data, err := os.ReadFile(path)
+		data, err := os.ReadFile(d.path(path))
 		if err == nil {
 			// El valor está en kHz, convertir a MHz
 			khz := strings.TrimSpace(string(data))
@@ -149,14 +261,15 @@ func getMaxCPUFrequency() float64 {
 	return 0
 }
 
-// detectMemory lee información de memoria desde /proc/meminfo y dmidecode
-func detectMemory() (MemoryInfo, error) {
+// detectMemory lee información de memoria desde /proc/meminfo, la tabla
+// SMBIOS y, si esta no está disponible, dmidecode
+func (d *Detector) detectMemory(ctx context.Context, rep *report) (MemoryInfo, error) {
 	mem := MemoryInfo{
 		Modules: make([]MemoryModule, 0),
 	}
 
 	// Leer memoria total desde /proc/meminfo
-	file, err := os.Open("/proc/meminfo")
+	file, err := os.Open(d.path("/proc/meminfo"))
 	if err != nil {
 		return mem, err
 	}
@@ -176,21 +289,54 @@ func detectMemory() (MemoryInfo, error) {
 		}
 	}
 
-	// Intentar obtener información detallada con dmidecode
-	modules := detectMemoryModules()
+	// Intentar obtener información detallada desde la tabla SMBIOS nativa
+	table, err := d.readSMBIOS()
+	if err != nil {
+		rep.note("tabla SMBIOS no accesible: %v", err)
+	} else {
+		mem.Modules = smbiosMemoryModules(table)
+		mem.InstalledBytes = table.MappedBytes
+		// Las placas multi-socket tienen un array por socket: ranuras y
+		// capacidad máxima se suman
+		var maxCapacity uint64
+		for _, a := range table.MemoryArrays {
+			if a.Use != 0x03 { // Solo memoria del sistema
+				continue
+			}
+			mem.Slots += a.Devices
+			maxCapacity += a.MaxCapacity
+			mem.ErrorCorrection = smbiosErrorCorrection[a.ErrorCorrection]
+		}
+		if maxCapacity > 0 {
+			mem.MaxCapacity = formatModuleSize(maxCapacity)
+		}
+	}
+
+	// Si no hubo tabla SMBIOS, intentar con dmidecode
+	if len(mem.Modules) > 0 {
+		rep.setSource("smbios")
+		return mem, scanner.Err()
+	}
+	modules, err := d.detectMemoryModules(ctx)
+	if err != nil {
+		rep.note("dmidecode: %v", err)
+	}
 	if len(modules) > 0 {
+		rep.setSource("dmidecode")
 		mem.Modules = modules
 	} else {
 		// Fallback: sintetizar entrada cuando dmidecode no está disponible
-		mem.Modules = fallbackMemoryModules(mem.TotalGB)
+		mem.Modules = d.fallbackMemoryModules(ctx, mem.TotalGB)
+		rep.setSource("fallback")
+		rep.degrade("sin detalle de módulos: solo se conoce la RAM total")
 	}
 
-	return mem, nil
+	return mem, scanner.Err()
 }
 
 // fallbackMemoryModules crea una entrada sintética con la RAM total cuando
 // dmidecode no está disponible o no devuelve información de módulos.
-func fallbackMemoryModules(totalGB float64) []MemoryModule {
+func (d *Detector) fallbackMemoryModules(ctx context.Context, totalGB float64) []MemoryModule {
 	if totalGB <= 0 {
 		return nil
 	}
@@ -202,7 +348,7 @@ func fallbackMemoryModules(totalGB float64) []MemoryModule {
 	}
 
 	// Intentar leer tipo de RAM desde dmidecode con timeout corto
-	out, err := exec.Command("dmidecode", "-s", "memory-type").Output()
+	out, err := d.run(ctx, "dmidecode", "-s", "memory-type")
 	if err == nil {
 		t := strings.TrimSpace(string(out))
 		if t != "" {
@@ -213,15 +359,15 @@ func fallbackMemoryModules(totalGB float64) []MemoryModule {
 	return []MemoryModule{module}
 }
 
-// detectMemoryModules usa dmidecode para obtener información de módulos RAM
-func detectMemoryModules() []MemoryModule {
+// detectMemoryModules usa dmidecode para obtener información de módulos RAM.
+// dmidecode puede no estar disponible o requerir privilegios; en ese caso se
+// devuelve el error junto con una lista vacía.
+func (d *Detector) detectMemoryModules(ctx context.Context) ([]MemoryModule, error) {
 	modules := make([]MemoryModule, 0)
 
-	cmd := exec.Command("dmidecode", "-t", "memory")
-	output, err := cmd.Output()
+	output, err := d.run(ctx, "dmidecode", "-t", "memory")
 	if err != nil {
-		// dmidecode puede no estar disponible o requiere privilegios
-		return modules
+		return modules, err
 	}
 
 	var currentModule *MemoryModule
@@ -261,31 +407,35 @@ func detectMemoryModules() []MemoryModule {
 		modules = append(modules, *currentModule)
 	}
 
-	return modules
+	return modules, nil
 }
 
-// detectDisks lee información de discos desde /sys/block
-func detectDisks() ([]DiskInfo, error) {
+// detectDisks lee información de discos desde /sys/block, su tabla de
+// particiones y, sobre el sistema en ejecución, su estado SMART
+func (d *Detector) detectDisks(ctx context.Context, rep *report) ([]DiskInfo, error) {
 	disks := make([]DiskInfo, 0)
 
-	entries, err := os.ReadDir("/sys/block")
+	entries, err := os.ReadDir(d.path("/sys/block"))
 	if err != nil {
 		return disks, err
 	}
 
 	for _, entry := range entries {
 		name := entry.Name()
-		// Ignorar dispositivos virtuales
+		// Ignorar dispositivos virtuales, los lógicos (md, dm), que se
+		// describen en Storage, y las áreas de arranque/RPMB de las eMMC,
+		// que forman parte de mmcblkN
 		if strings.HasPrefix(name, "loop") ||
 			strings.HasPrefix(name, "ram") ||
 			strings.HasPrefix(name, "dm-") ||
+			strings.HasPrefix(name, "md") ||
 			strings.HasPrefix(name, "zram") ||
-			strings.HasPrefix(name, "sr") {
+			(strings.HasPrefix(name, "mmcblk") && (strings.Contains(name, "boot") || strings.Contains(name, "rpmb"))) {
 			continue
 		}
 
 		disk := DiskInfo{Name: name}
-		basePath := "/sys/block/" + name
+		basePath := d.path("/sys/block/" + name)
 
 		// Tamaño del dispositivo (sectores de 512 bytes)
 		if data, err := os.ReadFile(basePath + "/size"); err == nil {
@@ -295,8 +445,14 @@ func detectDisks() ([]DiskInfo, error) {
 			}
 		}
 
-		// Ignorar dispositivos muy pequeños (< 1 MB)
-		if disk.SizeBytes < 1024*1024 {
+		optical := strings.HasPrefix(name, "sr")
+		if data, err := os.ReadFile(basePath + "/removable"); err == nil {
+			disk.Removable = strings.TrimSpace(string(data)) == "1"
+		}
+
+		// Ignorar dispositivos sin medio salvo unidades ópticas y lectores
+		// extraíbles, que se muestran vacíos
+		if disk.SizeBytes == 0 && !optical && !disk.Removable {
 			continue
 		}
 
@@ -316,17 +472,39 @@ func detectDisks() ([]DiskInfo, error) {
 			disk.Vendor = strings.TrimSpace(string(data))
 		}
 
-		// Tipo: NVMe, SSD o HDD
-		if strings.HasPrefix(name, "nvme") {
+		// Tipo: NVMe, óptico, eMMC/SD, SSD o HDD
+		switch {
+		case strings.HasPrefix(name, "nvme"):
 			disk.Type = "NVMe SSD"
-		} else if data, err := os.ReadFile(basePath + "/queue/rotational"); err == nil {
-			if strings.TrimSpace(string(data)) == "0" {
-				disk.Type = "SSD"
-			} else {
-				disk.Type = "HDD"
+		case optical:
+			disk.Type = "Optical"
+		case strings.HasPrefix(name, "mmcblk"):
+			// eMMC o SD según el CID, en readDiskIdentity
+		default:
+			if data, err := os.ReadFile(basePath + "/queue/rotational"); err == nil {
+				if strings.TrimSpace(string(data)) == "0" {
+					disk.Type = "SSD"
+				} else {
+					disk.Type = "HDD"
+				}
 			}
 		}
 
+		d.readDiskIdentity(&disk)
+		if optical {
+			d.readOptical(rep, &disk)
+		}
+		if disk.SizeBytes > 0 {
+			d.readPartitions(ctx, rep, &disk)
+		} else {
+			disk.Partitions = make([]Partition, 0)
+		}
+		disk.Contents = diskContents(disk)
+
+		if d.live() {
+			d.readDiskHealth(ctx, rep, &disk)
+		}
+
 		disks = append(disks, disk)
 	}
 
@@ -334,9 +512,9 @@ func detectDisks() ([]DiskInfo, error) {
 }
 
 // detectMotherboard lee información de la placa madre desde /sys/class/dmi/id
-func detectMotherboard() (MotherboardInfo, error) {
+func (d *Detector) detectMotherboard(rep *report) (MotherboardInfo, error) {
 	mb := MotherboardInfo{}
-	dmiPath := "/sys/class/dmi/id"
+	dmiPath := d.path("/sys/class/dmi/id")
 
 	files := map[string]*string{
 		"board_vendor":  &mb.Manufacturer,
@@ -348,81 +526,179 @@ func detectMotherboard() (MotherboardInfo, error) {
 		"bios_date":     &mb.BIOSDate,
 	}
 
+	found := 0
 	for filename, target := range files {
 		path := filepath.Join(dmiPath, filename)
 		data, err := os.ReadFile(path)
 		if err == nil {
 			*target = strings.TrimSpace(string(data))
+			found++
 		}
 	}
 
+	// Completar desde la tabla SMBIOS (tipo 0 y 2): incluye la etiqueta de
+	// inventario, que sysfs no expone
+	table, err := d.readSMBIOS()
+	if err == nil {
+		fillEmpty(&mb.Manufacturer, table.Baseboard.Manufacturer)
+		fillEmpty(&mb.Product, table.Baseboard.Product)
+		fillEmpty(&mb.Version, table.Baseboard.Version)
+		fillEmpty(&mb.SerialNumber, table.Baseboard.SerialNumber)
+		fillEmpty(&mb.AssetTag, table.Baseboard.AssetTag)
+		fillEmpty(&mb.BIOSVendor, table.BIOS.Vendor)
+		fillEmpty(&mb.BIOSVersion, table.BIOS.Version)
+		fillEmpty(&mb.BIOSDate, table.BIOS.ReleaseDate)
+	}
+
+	switch {
+	case found == 0 && err != nil:
+		rep.skip("sin datos DMI: /sys/class/dmi/id y tabla SMBIOS no accesibles")
+	case found == 0:
+		rep.setSource("smbios")
+	case err != nil:
+		rep.note("tabla SMBIOS no accesible: etiqueta de inventario no disponible")
+	}
+
 	return mb, nil
 }
 
-// detectGPU usa lspci para detectar tarjetas gráficas
-func detectGPU() ([]GPUInfo, error) {
-	gpus := make([]GPUInfo, 0)
+// detectSystem obtiene la identificación del producto y del chasis desde
+// /sys/class/dmi/id y la completa con la tabla SMBIOS (tipo 1 y 3)
+func (d *Detector) detectSystem(rep *report) (SystemInfo, error) {
+	sys := SystemInfo{}
 
-	cmd := exec.Command("lspci")
-	output, err := cmd.Output()
-	if err != nil {
-		return gpus, err
+	files := map[string]*string{
+		"sys_vendor":        &sys.Manufacturer,
+		"product_name":      &sys.Product,
+		"product_version":   &sys.Version,
+		"product_serial":    &sys.SerialNumber,
+		"product_uuid":      &sys.UUID,
+		"product_sku":       &sys.SKU,
+		"product_family":    &sys.Family,
+		"chassis_vendor":    &sys.ChassisVendor,
+		"chassis_serial":    &sys.ChassisSerial,
+		"chassis_asset_tag": &sys.ChassisAssetTag,
+	}
+
+	found := 0
+	restricted := false
+	for filename, target := range files {
+		v, err := d.readString("/sys/class/dmi/id/" + filename)
+		if err != nil {
+			// Los números de serie y el UUID solo son legibles como root
+			restricted = restricted || os.IsPermission(err)
+			continue
+		}
+		*target = v
+		found++
+	}
+	// chassis_type es el código numérico SMBIOS (3 = Desktop, 10 = Notebook...)
+	if v, err := d.readString("/sys/class/dmi/id/chassis_type"); err == nil {
+		if n, err := strconv.Atoi(v); err == nil {
+			sys.ChassisType = chassisTypes[n]
+			found++
+		}
 	}
 
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		// Buscar líneas que contengan VGA o 3D
-		if strings.Contains(line, "VGA compatible controller") ||
-			strings.Contains(line, "3D controller") ||
-			strings.Contains(line, "Display controller") {
+	table, err := d.readSMBIOS()
+	if err == nil {
+		fillEmpty(&sys.Manufacturer, table.System.Manufacturer)
+		fillEmpty(&sys.Product, table.System.Product)
+		fillEmpty(&sys.Version, table.System.Version)
+		fillEmpty(&sys.SerialNumber, table.System.SerialNumber)
+		fillEmpty(&sys.UUID, table.System.UUID)
+		fillEmpty(&sys.SKU, table.System.SKU)
+		fillEmpty(&sys.Family, table.System.Family)
+		fillEmpty(&sys.ChassisType, chassisTypes[table.Chassis.Type])
+		fillEmpty(&sys.ChassisVendor, table.Chassis.Manufacturer)
+		fillEmpty(&sys.ChassisSerial, table.Chassis.SerialNumber)
+		fillEmpty(&sys.ChassisAssetTag, table.Chassis.AssetTag)
+	}
+
+	switch {
+	case found == 0 && err != nil:
+		if !os.IsNotExist(err) && !os.IsPermission(err) {
+			return sys, err
+		}
+		// Sin DMI accesible (firmware sin DMI, ej: muchas placas ARM)
+		rep.skip("sin datos DMI: /sys/class/dmi/id y tabla SMBIOS no accesibles")
+	case found == 0:
+		rep.setSource("smbios")
+	case err != nil && restricted:
+		rep.note("números de serie y UUID solo legibles como root")
+	}
 
-			parts := strings.SplitN(line, " ", 2)
-			if len(parts) < 2 {
-				continue
-			}
+	return sys, nil
+}
 
-			pciAddress := strings.TrimSpace(parts[0])
-			description := strings.TrimSpace(parts[1])
+// fillEmpty asigna value a *target solo si *target está vacío
+func fillEmpty(target *string, value string) {
+	if *target == "" {
+		*target = value
+	}
+}
 
-			// Extraer información del GPU
-			gpu := GPUInfo{
-				PCIAddress: pciAddress,
-			}
+// detectGPU obtiene las tarjetas gráficas (clase PCI 0x03) a partir de los
+// dispositivos PCI enumerados desde sysfs
+func (d *Detector) detectGPU(ctx context.Context, rep *report) ([]GPUInfo, error) {
+	gpus := make([]GPUInfo, 0)
 
-			// Detectar vendor
-			descLower := strings.ToLower(description)
-			if strings.Contains(descLower, "nvidia") {
-				gpu.Vendor = "NVIDIA"
-			} else if strings.Contains(descLower, "amd") || strings.Contains(descLower, "ati") {
-				gpu.Vendor = "AMD"
-			} else if strings.Contains(descLower, "intel") {
-				gpu.Vendor = "Intel"
-			}
+	devices, err := d.pciDevices()
+	if err != nil {
+		return gpus, err
+	}
 
-			// El modelo es toda la descripción después del tipo
-			if idx := strings.Index(description, ":"); idx > 0 {
-				gpu.Model = strings.TrimSpace(description[idx+1:])
-			} else {
-				gpu.Model = description
-			}
+	for _, dev := range devices {
+		// Clase 03: VGA compatible, XGA, 3D controller y Display controller
+		if !strings.HasPrefix(dev.Class, "03") {
+			continue
+		}
 
-			// Detectar VRAM
-			gpu.MemorySize = getVRAM(pciAddress)
+		gpu := GPUInfo{
+			Vendor:     gpuVendorName(dev),
+			Model:      dev.Device,
+			PCIAddress: dev.Address,
+			Driver:     dev.Driver,
+		}
+		if gpu.Model == "" {
+			gpu.Model = "Device " + dev.DeviceID
+		}
 
-			gpus = append(gpus, gpu)
+		// Detectar VRAM
+		gpu.MemorySize, gpu.VRAMSource = d.getVRAM(ctx, dev.Address)
+		if gpu.MemorySize == "" {
+			rep.note("VRAM no detectada para %s", dev.Address)
 		}
+		d.readGPUDetails(&gpu)
+
+		gpus = append(gpus, gpu)
 	}
 
 	return gpus, nil
 }
 
+// gpuVendorName devuelve el nombre corto del fabricante de una GPU a partir
+// de su ID PCI, o el nombre completo de pci.ids para fabricantes menos comunes
+func gpuVendorName(dev PCIDevice) string {
+	switch dev.VendorID {
+	case "10de":
+		return "NVIDIA"
+	case "1002", "1022":
+		return "AMD"
+	case "8086":
+		return "Intel"
+	}
+	return dev.Vendor
+}
+
 // getVRAM intenta detectar la VRAM de una GPU a partir de su dirección PCI.
+// Devuelve el tamaño y la estrategia que lo obtuvo (VRAMSource*).
 //
 //   - Estrategia 1: /proc/driver/nvidia/gpus/<addr>/information  (NVIDIA driver propietario)
 //   - Estrategia 2: nvidia-smi --query-gpu=memory.total          (si nvidia-smi está presente)
 //   - Estrategia 3: sysfs DRM mem_info_vram_total                (AMD / NVIDIA open)
-//   - Estrategia 4: lspci -v BAR prefetchable >= 512 MB          (último recurso; filtra apertura 256MB)
-func getVRAM(pciAddress string) string {
+//   - Estrategia 4: BAR prefetchable más grande en sysfs         (último recurso; puede ser solo la apertura)
+func (d *Detector) getVRAM(ctx context.Context, pciAddress string) (string, string) {
 	// Normalizar dirección: lspci puede omitir el dominio "0000:"
 	fullAddr := pciAddress
 	if len(strings.Split(pciAddress, ":")) == 2 {
@@ -432,7 +708,7 @@ func getVRAM(pciAddress string) string {
 	// Estrategia 1: /proc/driver/nvidia/gpus/<addr>/information
 	// El kernel NVIDIA escribe aquí "Video Memory: 4096 MB"
 	for _, candidate := range []string{fullAddr, pciAddress} {
-		infoPath := "/proc/driver/nvidia/gpus/" + candidate + "/information"
+		infoPath := d.path("/proc/driver/nvidia/gpus/" + candidate + "/information")
 		if data, err := os.ReadFile(infoPath); err == nil {
 			for _, line := range strings.Split(string(data), "\n") {
 				if strings.HasPrefix(line, "Video Memory:") {
@@ -440,7 +716,7 @@ func getVRAM(pciAddress string) string {
 					// formato: "4096 MB" o "4096MB"
 					val = strings.ReplaceAll(val, " ", "")
 					if b := parseVRAMSize(val); b > 0 {
-						return formatVRAMBytes(b)
+						return formatVRAMBytes(b), VRAMSourceNVIDIAProc
 					}
 				}
 			}
@@ -448,20 +724,19 @@ func getVRAM(pciAddress string) string {
 	}
 
 	// Estrategia 2: nvidia-smi (devuelve MiB, ej: "4096 MiB" o solo "4096")
-	if out, err := exec.Command("nvidia-smi",
+	if out, err := d.run(ctx, "nvidia-smi",
 		"--query-gpu=memory.total",
-
 		"--format=csv,noheader,nounits",
-		"--id="+fullAddr).Output(); err == nil {
+		"--id="+fullAddr); err == nil {
 		val := strings.TrimSpace(string(out))
 		// nounits → valor en MiB como número entero
 		if mib, err := strconv.ParseUint(val, 10, 64); err == nil && mib > 0 {
-			return formatVRAMBytes(mib * 1024 * 1024)
+			return formatVRAMBytes(mib * 1024 * 1024), VRAMSourceNVIDIASMI
 		}
 	}
 
 	// Estrategia 3: sysfs DRM — mem_info_vram_total (AMD + módulo open NVIDIA)
-	cards, _ := filepath.Glob("/sys/class/drm/card*/device")
+	cards, _ := filepath.Glob(d.path("/sys/class/drm/card*/device"))
 	for _, cardDev := range cards {
 		resolved, err := filepath.EvalSymlinks(cardDev)
 		if err != nil {
@@ -470,43 +745,57 @@ func getVRAM(pciAddress string) string {
 		if strings.HasSuffix(resolved, fullAddr) || strings.HasSuffix(resolved, pciAddress) {
 			if data, err := os.ReadFile(cardDev + "/mem_info_vram_total"); err == nil {
 				if b, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64); err == nil && b > 0 {
-					return formatVRAMBytes(b)
+					return formatVRAMBytes(b), VRAMSourceDRM
 				}
 			}
 		}
 	}
 
-	// Estrategia 4: lspci -v — BAR prefetchable mayor.
+	// Estrategia 4: BAR prefetchable mayor desde /sys/bus/pci/devices/<addr>/resource.
 	// Con driver propietario NVIDIA el BAR es 256 MB (apertura), pero
 	// si ninguna estrategia anterior tuvo éxito es mejor mostrar eso
 	// que no mostrar nada.
-	out, err := exec.Command("lspci", "-v", "-s", pciAddress).Output()
+	if b := d.largestPrefetchableBAR(fullAddr); b > 0 {
+		return formatVRAMBytes(b), VRAMSourceBAR
+	}
+	return "", ""
+}
+
+// largestPrefetchableBAR devuelve el tamaño del mayor BAR de memoria
+// prefetchable del dispositivo. Cada línea de "resource" tiene el formato
+// "inicio fin flags" en hexadecimal.
+func (d *Detector) largestPrefetchableBAR(pciAddress string) uint64 {
+	const (
+		ioresourceMem      = 0x00000200
+		ioresourcePrefetch = 0x00002000
+	)
+
+	data, err := d.readString("/sys/bus/pci/devices/" + pciAddress + "/resource")
 	if err != nil {
-		return ""
+		return 0
 	}
 
 	var maxBytes uint64
-	for _, line := range strings.Split(string(out), "\n") {
-		line = strings.TrimSpace(line)
-		if !strings.Contains(line, "prefetchable") || strings.Contains(line, "non-prefetchable") {
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
 			continue
 		}
-		idx := strings.Index(line, "[size=")
-		if idx < 0 {
+		start, err1 := strconv.ParseUint(strings.TrimPrefix(fields[0], "0x"), 16, 64)
+		end, err2 := strconv.ParseUint(strings.TrimPrefix(fields[1], "0x"), 16, 64)
+		flags, err3 := strconv.ParseUint(strings.TrimPrefix(fields[2], "0x"), 16, 64)
+		if err1 != nil || err2 != nil || err3 != nil || end <= start {
 			continue
 		}
-		sizeStr := line[idx+6:]
-		if end := strings.Index(sizeStr, "]"); end > 0 {
-			sizeStr = sizeStr[:end]
+		if flags&ioresourceMem == 0 || flags&ioresourcePrefetch == 0 {
+			continue
 		}
-		if b := parseVRAMSize(sizeStr); b > maxBytes {
-			maxBytes = b
+		if size := end - start + 1; size > maxBytes {
+			maxBytes = size
 		}
 	}
-	if maxBytes > 0 {
-		return formatVRAMBytes(maxBytes)
-	}
-	return ""
+
+	return maxBytes
 }
 
 // parseVRAMSize convierte strings como "8G", "256M", "512K" a bytes.
@@ -0,0 +1,91 @@
+package hardware
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Estados de un detector en HardwareInfo.Diagnostics
+const (
+	StatusOK      = "ok"      // Detección completa
+	StatusPartial = "partial" // Hubo datos, pero con fuentes degradadas o timeout
+	StatusFailed  = "failed"  // Sin datos por error
+	StatusSkipped = "skipped" // No aplica o no hay acceso a la fuente
+)
+
+// report acumula el estado de un detector mientras se ejecuta. Es seguro
+// para uso concurrente: un detector abandonado por timeout puede seguir
+// escribiendo mientras se genera el diagnóstico.
+type report struct {
+	mu       sync.Mutex
+	source   string
+	partial  bool
+	skipped  bool
+	messages []string
+}
+
+// setSource registra la fuente de datos que usó el detector
+func (r *report) setSource(source string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.source = source
+}
+
+// note agrega un mensaje informativo sin cambiar el estado
+func (r *report) note(format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, fmt.Sprintf(format, args...))
+}
+
+// degrade agrega un mensaje y marca el resultado como parcial
+func (r *report) degrade(format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.partial = true
+	r.messages = append(r.messages, fmt.Sprintf(format, args...))
+}
+
+// skip agrega un mensaje y marca el detector como omitido
+func (r *report) skip(format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.skipped = true
+	r.messages = append(r.messages, fmt.Sprintf(format, args...))
+}
+
+// diagnostic genera la entrada de Diagnostics a partir del reporte y del
+// resultado final del detector
+func (r *report) diagnostic(name string, res detectorResult, elapsed time.Duration) DetectorDiagnostic {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	diag := DetectorDiagnostic{
+		Name:       name,
+		Status:     StatusOK,
+		Source:     r.source,
+		DurationMS: elapsed.Milliseconds(),
+		Messages:   append(make([]string, 0, len(r.messages)+2), r.messages...),
+	}
+
+	switch {
+	case res.err != nil:
+		diag.Status = StatusFailed
+		diag.Messages = append(diag.Messages, res.err.Error())
+	case r.skipped:
+		diag.Status = StatusSkipped
+	case r.partial:
+		diag.Status = StatusPartial
+	}
+
+	if res.timedOut {
+		diag.TimedOut = true
+		diag.Messages = append(diag.Messages, "tiempo límite excedido")
+		if diag.Status == StatusOK {
+			diag.Status = StatusPartial
+		}
+	}
+
+	return diag
+}
@@ -0,0 +1,124 @@
+package hardware
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// readDiskIdentity completa la identificación de un disco (serie, WWN,
+// firmware, transporte) y la geometría de su cola desde /sys/block/<name>
+func (d *Detector) readDiskIdentity(disk *DiskInfo) {
+	base := "/sys/block/" + disk.Name
+
+	disk.Transport = d.diskTransport(base + "/device")
+
+	switch {
+	case strings.HasPrefix(disk.Name, "nvme"):
+		// device apunta al controlador (nvme0); el espacio de nombres
+		// (nvme0n1) tiene su propio nsid y wwid
+		disk.Serial, _ = d.readString(base + "/device/serial")
+		disk.Firmware, _ = d.readString(base + "/device/firmware_rev")
+		if v, err := d.readString(base + "/nsid"); err == nil {
+			disk.NVMeNamespaceID, _ = strconv.Atoi(v)
+		}
+		if v, err := d.readString(base + "/device/cntlid"); err == nil {
+			disk.NVMeControllerID, _ = strconv.Atoi(v)
+		}
+		disk.WWN = diskWWN(d.readFirst(base+"/wwid", base+"/device/wwid"))
+
+	case strings.HasPrefix(disk.Name, "mmcblk"):
+		d.readMMCIdentity(disk)
+
+	case strings.HasPrefix(disk.Name, "vd"):
+		disk.Serial, _ = d.readString(base + "/serial")
+
+	default:
+		// SCSI/SATA/SAS/USB: serie en la página VPD 80h, firmware en rev
+		disk.Serial = d.vpdSerial(base + "/device/vpd_pg80")
+		disk.Firmware, _ = d.readString(base + "/device/rev")
+		disk.WWN = diskWWN(d.readFirst(base+"/device/wwid", base+"/wwid"))
+	}
+
+	disk.LogicalBlockSize = d.readInt(base + "/queue/logical_block_size")
+	disk.PhysicalBlockSize = d.readInt(base + "/queue/physical_block_size")
+	if v, err := d.readString(base + "/queue/discard_max_bytes"); err == nil {
+		disk.Discard = v != "" && v != "0"
+	}
+}
+
+// diskTransport deduce el bus por el que está conectado un disco a partir
+// de la ruta real de su dispositivo en /sys/devices
+func (d *Detector) diskTransport(devLink string) string {
+	target, err := filepath.EvalSymlinks(d.path(devLink))
+	if err != nil {
+		return ""
+	}
+	p := filepath.ToSlash(target) + "/"
+
+	// El orden importa: un disco USB-SATA cuelga de usbN sin pasar por ataN
+	switch {
+	case strings.Contains(p, "/usb"):
+		return "USB"
+	case strings.Contains(p, "/nvme"):
+		return "NVMe"
+	case strings.Contains(p, "/mmc_host/"):
+		return "MMC"
+	case strings.Contains(p, "/ata"):
+		return "SATA"
+	case strings.Contains(p, "/end_device-"), strings.Contains(p, "/sas_"):
+		return "SAS"
+	case strings.Contains(p, "/virtio"):
+		return "virtio"
+	case strings.Contains(p, "/session"):
+		return "iSCSI"
+	}
+	return ""
+}
+
+// vpdSerial extrae el número de serie de la página VPD 80h (Unit Serial
+// Number): 4 bytes de cabecera, con la longitud en los bytes 2-3
+func (d *Detector) vpdSerial(p string) string {
+	data, err := os.ReadFile(d.path(p))
+	if err != nil || len(data) < 4 || data[1] != 0x80 {
+		return ""
+	}
+	n := int(data[2])<<8 | int(data[3])
+	if 4+n > len(data) {
+		n = len(data) - 4
+	}
+	return strings.TrimSpace(strings.Trim(string(data[4:4+n]), "\x00"))
+}
+
+// diskWWN convierte el wwid de sysfs en un WWN cuando es un identificador
+// NAA o EUI-64 ("naa.5000c500a1b2c3d4" -> "0x5000c500a1b2c3d4"). Los wwid
+// construidos a partir de fabricante, modelo y serie (t10.) no son WWN.
+func diskWWN(wwid string) string {
+	for _, prefix := range []string{"naa.", "eui."} {
+		if id, ok := strings.CutPrefix(wwid, prefix); ok {
+			return "0x" + strings.ToLower(id)
+		}
+	}
+	return ""
+}
+
+// readFirst devuelve el contenido del primer archivo legible de paths
+func (d *Detector) readFirst(paths ...string) string {
+	for _, p := range paths {
+		if s, err := d.readString(p); err == nil && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// readInt lee un entero decimal de sysfs (0 si no existe o no es válido)
+func (d *Detector) readInt(p string) int {
+	s, err := d.readString(p)
+	if err != nil {
+		return 0
+	}
+	v, _ := strconv.Atoi(s)
+	return v
+}
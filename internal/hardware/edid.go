@@ -0,0 +1,324 @@
+package hardware
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Tamaño de un bloque EDID (base y cada extensión)
+const edidBlockSize = 128
+
+// Etiquetas de los bloques de extensión y de los descriptores de monitor
+const (
+	edidExtCTA          = 0x02
+	edidDescSerial      = 0xFF
+	edidDescText        = 0xFE
+	edidDescName        = 0xFC
+	edidDescRangeLimits = 0xFD
+)
+
+// Bloques de datos CTA-861 que se resumen en DisplayInfo.Features
+const (
+	ctaTagVendor      = 3
+	ctaTagExtended    = 7
+	ctaExtHDRStatic   = 0x06
+	ctaExtYCbCr420    = 0x0E
+	ctaExtYCbCr420Cap = 0x0F
+)
+
+var edidHeader = []byte{0x00, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x00}
+
+// drmConnector reconoce los conectores de /sys/class/drm (card0-eDP-1,
+// card1-HDMI-A-1) frente a las tarjetas y los nodos render
+var drmConnector = regexp.MustCompile(`^(card\d+)-(.+)$`)
+
+// edidInterfaces son las interfaces digitales del byte 20 en EDID 1.4
+var edidInterfaces = map[byte]string{
+	1: "DVI",
+	2: "HDMI",
+	3: "HDMI",
+	4: "MDDI",
+	5: "DisplayPort",
+}
+
+// pnpVendors relaciona los códigos PNP más habituales en EDID con el
+// fabricante: marcas de monitores y de paneles de portátil
+var pnpVendors = map[string]string{
+	"ACR": "Acer",
+	"AOC": "AOC",
+	"APP": "Apple",
+	"AUO": "AU Optronics",
+	"AUS": "ASUS",
+	"BNQ": "BenQ",
+	"BOE": "BOE",
+	"CMN": "Chimei Innolux",
+	"CSO": "CSOT",
+	"DEL": "Dell",
+	"ENC": "EIZO",
+	"GSM": "LG Electronics",
+	"HSD": "HannStar",
+	"HWP": "HP",
+	"IVM": "Iiyama",
+	"IVO": "InfoVision",
+	"LEN": "Lenovo",
+	"LGD": "LG Display",
+	"LPL": "LG Philips",
+	"MSI": "MSI",
+	"NEC": "NEC",
+	"PHL": "Philips",
+	"QDS": "Quanta Display",
+	"RHT": "Red Hat (QEMU)",
+	"SAM": "Samsung",
+	"SDC": "Samsung Display",
+	"SHP": "Sharp",
+	"SNY": "Sony",
+	"TSB": "Toshiba",
+	"VSC": "ViewSonic",
+}
+
+// detectDisplays lista los monitores y paneles conectados a las GPU a partir
+// de los conectores DRM de /sys/class/drm, decodificando su EDID
+func (d *Detector) detectDisplays(rep *report) ([]DisplayInfo, error) {
+	displays := make([]DisplayInfo, 0)
+
+	if !d.exists("/sys/class/drm") {
+		rep.skip("sin /sys/class/drm: ninguna GPU con driver DRM")
+		return displays, nil
+	}
+
+	for _, name := range d.listDir("/sys/class/drm") {
+		m := drmConnector.FindStringSubmatch(name)
+		if m == nil {
+			continue
+		}
+		base := "/sys/class/drm/" + name
+		status, _ := d.readString(base + "/status")
+		edid, _ := os.ReadFile(d.path(base + "/edid"))
+		if status != "connected" && len(edid) == 0 {
+			continue
+		}
+
+		display := DisplayInfo{
+			Connector:  m[2],
+			Card:       m[1],
+			GPUAddress: d.linkBase("/sys/class/drm/" + m[1] + "/device"),
+			Internal:   internalConnector(m[2]),
+			Status:     status,
+			Features:   make([]string, 0),
+		}
+		if len(edid) == 0 {
+			rep.note("%s: conectado sin EDID", display.Connector)
+		} else if err := decodeEDID(edid, &display); err != nil {
+			rep.degrade("%s: EDID no válido: %v", display.Connector, err)
+		}
+		displays = append(displays, display)
+	}
+
+	// Los paneles integrados primero
+	sort.SliceStable(displays, func(i, j int) bool {
+		return displays[i].Internal && !displays[j].Internal
+	})
+	return displays, nil
+}
+
+// internalConnector indica si un conector DRM corresponde a un panel
+// integrado (portátiles, tabletas, todo en uno)
+func internalConnector(connector string) bool {
+	for _, prefix := range []string{"eDP", "LVDS", "DSI"} {
+		if strings.HasPrefix(connector, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeEDID interpreta el bloque base de EDID 1.3/1.4 (fabricante, producto,
+// serie, fecha, tamaño, modo nativo, nombre y límites de refresco) y los
+// bloques de extensión CTA-861
+func decodeEDID(b []byte, display *DisplayInfo) error {
+	if len(b) < edidBlockSize {
+		return fmt.Errorf("%d bytes (se esperaban al menos %d)", len(b), edidBlockSize)
+	}
+	if !bytes.Equal(b[:8], edidHeader) {
+		return errors.New("cabecera incorrecta")
+	}
+	if !edidChecksumOK(b[:edidBlockSize]) {
+		return errors.New("checksum incorrecto en el bloque base")
+	}
+
+	// Código PNP: tres letras de 5 bits ('A' = 1) en big endian
+	id := binary.BigEndian.Uint16(b[8:])
+	display.ManufacturerID = string([]byte{
+		byte(id>>10&0x1F) + 'A' - 1,
+		byte(id>>5&0x1F) + 'A' - 1,
+		byte(id&0x1F) + 'A' - 1,
+	})
+	display.Manufacturer = pnpVendors[display.ManufacturerID]
+	display.ProductCode = fmt.Sprintf("%04X", binary.LittleEndian.Uint16(b[10:]))
+	if serial := binary.LittleEndian.Uint32(b[12:]); serial != 0 {
+		display.SerialNumber = fmt.Sprint(serial)
+	}
+
+	// Semana 0xFF: el año es el del modelo, no el de fabricación
+	switch week := int(b[16]); week {
+	case 0xFF:
+		display.ModelYear = true
+	default:
+		if week <= 54 {
+			display.Week = week
+		}
+	}
+	display.Year = 1990 + int(b[17])
+	display.EDIDVersion = fmt.Sprintf("%d.%d", b[18], b[19])
+
+	if input := b[20]; input&0x80 != 0 && b[19] >= 4 {
+		display.Interface = edidInterfaces[input&0x0F]
+		if depth := int(input >> 4 & 0x07); depth > 0 && depth < 7 {
+			display.BitDepth = 4 + 2*depth
+		}
+	}
+
+	// Tamaño en cm; el del modo preferido, en mm, es más preciso
+	display.WidthMM = int(b[21]) * 10
+	display.HeightMM = int(b[22]) * 10
+
+	text := ""
+	for i := 0; i < 4; i++ {
+		desc := b[54+i*18 : 72+i*18]
+		if desc[0] != 0 || desc[1] != 0 {
+			// El primer descriptor de temporización es el modo preferido
+			if i == 0 {
+				decodeDetailedTiming(desc, display)
+			}
+			continue
+		}
+		switch desc[3] {
+		case edidDescName:
+			display.Model = edidDescriptorString(desc)
+		case edidDescSerial:
+			display.SerialNumber = edidDescriptorString(desc)
+		case edidDescText:
+			text = edidDescriptorString(desc)
+		case edidDescRangeLimits:
+			minAdd, maxAdd := 0, 0
+			if desc[4]&0x03 == 0x03 {
+				minAdd = 255
+			}
+			if desc[4]&0x02 != 0 {
+				maxAdd = 255
+			}
+			display.MinRefreshHz = int(desc[5]) + minAdd
+			display.MaxRefreshHz = int(desc[6]) + maxAdd
+		}
+	}
+
+	// Los paneles de portátil no traen nombre: el número de pieza es el
+	// último texto libre (el primero suele ser el fabricante)
+	if display.Model == "" {
+		display.Model = text
+	}
+
+	if display.WidthMM > 0 && display.HeightMM > 0 {
+		display.DiagonalInches = math.Round(math.Hypot(float64(display.WidthMM), float64(display.HeightMM))/25.4*10) / 10
+	}
+
+	// Bloques de extensión: solo se interpretan los CTA-861 (HDMI, HDR, audio)
+	for n := 1; n <= int(b[126]) && (n+1)*edidBlockSize <= len(b); n++ {
+		ext := b[n*edidBlockSize : (n+1)*edidBlockSize]
+		if ext[0] == edidExtCTA && edidChecksumOK(ext) {
+			decodeCTA(ext, display)
+		}
+	}
+	return nil
+}
+
+// decodeDetailedTiming obtiene del descriptor de temporización del modo
+// preferido la resolución nativa, el refresco y el tamaño de imagen en mm
+func decodeDetailedTiming(desc []byte, display *DisplayInfo) {
+	clock := float64(binary.LittleEndian.Uint16(desc)) * 10000 // Hz
+	hActive := int(desc[2]) | int(desc[4]&0xF0)<<4
+	hBlank := int(desc[3]) | int(desc[4]&0x0F)<<8
+	vActive := int(desc[5]) | int(desc[7]&0xF0)<<4
+	vBlank := int(desc[6]) | int(desc[7]&0x0F)<<8
+
+	display.NativeWidth = hActive
+	display.NativeHeight = vActive
+	if total := float64((hActive + hBlank) * (vActive + vBlank)); total > 0 {
+		display.NativeRefreshHz = math.Round(clock/total*100) / 100
+	}
+	if desc[17]&0x80 != 0 {
+		// Entrelazado: cada campo tiene la mitad de las líneas
+		display.NativeHeight *= 2
+	}
+
+	wMM := int(desc[12]) | int(desc[14]&0xF0)<<4
+	hMM := int(desc[13]) | int(desc[14]&0x0F)<<8
+	if wMM > 0 && hMM > 0 {
+		display.WidthMM, display.HeightMM = wMM, hMM
+	}
+}
+
+// decodeCTA resume un bloque de extensión CTA-861: audio básico y los
+// bloques de datos de HDMI (VSDB), HDMI Forum, HDR y YCbCr 4:2:0
+func decodeCTA(ext []byte, display *DisplayInfo) {
+	if ext[1] >= 2 && ext[3]&0x40 != 0 {
+		display.Features = appendUnique(display.Features, "audio")
+	}
+
+	// Colección de bloques de datos: desde el byte 4 hasta el offset de los
+	// descriptores de temporización (byte 2)
+	end := min(int(ext[2]), len(ext)-1)
+	for i := 4; i < end; {
+		tag, n := ext[i]>>5, int(ext[i]&0x1F)
+		if i+1+n > end {
+			break
+		}
+		block := ext[i+1 : i+1+n]
+		i += 1 + n
+
+		switch {
+		case tag == ctaTagVendor && n >= 3:
+			switch oui := uint32(block[0]) | uint32(block[1])<<8 | uint32(block[2])<<16; oui {
+			case 0x000C03:
+				display.Features = appendUnique(display.Features, "HDMI")
+			case 0xC45DD8:
+				display.Features = appendUnique(display.Features, "HDMI 2.x")
+			}
+		case tag == ctaTagExtended && n >= 1:
+			switch block[0] {
+			case ctaExtHDRStatic:
+				if n >= 2 && block[1]&0x04 != 0 {
+					display.Features = appendUnique(display.Features, "HDR10")
+				}
+				if n >= 2 && block[1]&0x08 != 0 {
+					display.Features = appendUnique(display.Features, "HLG")
+				}
+			case ctaExtYCbCr420, ctaExtYCbCr420Cap:
+				display.Features = appendUnique(display.Features, "YCbCr 4:2:0")
+			}
+		}
+	}
+}
+
+// edidDescriptorString devuelve el texto de un descriptor de monitor (13
+// caracteres terminados en salto de línea y rellenos con espacios)
+func edidDescriptorString(desc []byte) string {
+	s, _, _ := strings.Cut(string(desc[5:18]), "\n")
+	return strings.TrimSpace(s)
+}
+
+// edidChecksumOK comprueba que los 128 bytes de un bloque sumen 0 módulo 256
+func edidChecksumOK(block []byte) bool {
+	var sum byte
+	for _, v := range block {
+		sum += v
+	}
+	return sum == 0
+}
@@ -0,0 +1,111 @@
+package hardware
+
+import (
+	"net"
+	"runtime"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// Constantes de la interfaz ethtool del kernel (linux/sockios.h y
+// linux/ethtool.h)
+const (
+	siocEthtool      = 0x8946
+	ethtoolGDrvInfo  = 0x00000003
+	ethtoolGPermAddr = 0x00000020
+	maxAddrLen       = 32
+)
+
+// ifreqData es struct ifreq con el campo ifr_data (puntero a la estructura
+// ethtool); el relleno cubre el tamaño de la unión
+type ifreqData struct {
+	name [16]byte
+	data unsafe.Pointer
+	_    [24]byte
+}
+
+// ethtoolDrvInfo es struct ethtool_drvinfo
+type ethtoolDrvInfo struct {
+	cmd         uint32
+	driver      [32]byte
+	version     [32]byte
+	fwVersion   [32]byte
+	busInfo     [32]byte
+	eromVersion [32]byte
+	reserved2   [12]byte
+	nPrivFlags  uint32
+	nStats      uint32
+	testInfoLen uint32
+	eedumpLen   uint32
+	regdumpLen  uint32
+}
+
+// ethtoolPermAddr es struct ethtool_perm_addr con espacio para MAX_ADDR_LEN
+type ethtoolPermAddr struct {
+	cmd  uint32
+	size uint32
+	data [maxAddrLen]byte
+}
+
+// ethtoolInfo es lo que hwscan obtiene de ethtool para una interfaz
+type ethtoolInfo struct {
+	driver       string
+	firmware     string
+	permanentMAC string
+}
+
+// ethtoolQuery consulta el driver, la versión de firmware y la MAC permanente
+// de una interfaz mediante el ioctl SIOCETHTOOL. Solo funciona sobre el
+// sistema en ejecución; los campos que el driver no implementa quedan vacíos.
+func ethtoolQuery(ifname string) (ethtoolInfo, error) {
+	var info ethtoolInfo
+
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM, 0)
+	if err != nil {
+		return info, err
+	}
+	defer syscall.Close(fd)
+
+	drv := ethtoolDrvInfo{cmd: ethtoolGDrvInfo}
+	if err := ethtoolIoctl(fd, ifname, unsafe.Pointer(&drv)); err != nil {
+		return info, err
+	}
+	info.driver = cString(drv.driver[:])
+	info.firmware = cString(drv.fwVersion[:])
+	if info.firmware == "N/A" {
+		info.firmware = ""
+	}
+
+	perm := ethtoolPermAddr{cmd: ethtoolGPermAddr, size: maxAddrLen}
+	if err := ethtoolIoctl(fd, ifname, unsafe.Pointer(&perm)); err == nil && perm.size > 0 && perm.size <= maxAddrLen {
+		mac := net.HardwareAddr(perm.data[:perm.size]).String()
+		if strings.Trim(mac, "0:") != "" {
+			info.permanentMAC = mac
+		}
+	}
+
+	return info, nil
+}
+
+// ethtoolIoctl ejecuta un comando ethtool sobre la interfaz ifname
+func ethtoolIoctl(fd int, ifname string, data unsafe.Pointer) error {
+	var req ifreqData
+	copy(req.name[:len(req.name)-1], ifname)
+	req.data = data
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), siocEthtool, uintptr(unsafe.Pointer(&req)))
+	runtime.KeepAlive(data)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// cString convierte un arreglo de bytes terminado en NUL a string
+func cString(b []byte) string {
+	if i := strings.IndexByte(string(b), 0); i >= 0 {
+		b = b[:i]
+	}
+	return strings.TrimSpace(string(b))
+}
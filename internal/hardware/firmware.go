@@ -0,0 +1,235 @@
+package hardware
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Modos de arranque de FirmwareInfo.BootMode
+const (
+	BootModeUEFI   = "UEFI"
+	BootModeLegacy = "BIOS"
+)
+
+// efiGlobalVariable es el GUID de las variables globales de UEFI
+// (SecureBoot, SetupMode, BootOrder, Boot####...)
+const efiGlobalVariable = "8be4df61-93ca-11d2-aa0d-00e098032b8c"
+
+// efiLoadOptionActive es el bit de atributo de una entrada Boot#### activa
+const efiLoadOptionActive = 0x1
+
+// esrtSystemFirmware es el fw_type de la entrada ESRT del firmware del sistema
+const esrtSystemFirmware = 1
+
+// detectFirmware describe el entorno de arranque: UEFI o BIOS heredado,
+// Secure Boot y Setup Mode, el orden y las entradas de arranque (variables
+// de efivarfs) y la revisión del firmware UEFI (tabla ESRT)
+func (d *Detector) detectFirmware(rep *report) (FirmwareInfo, error) {
+	fw := FirmwareInfo{
+		BootOrder:   make([]string, 0),
+		BootEntries: make([]BootEntry, 0),
+	}
+
+	if !d.exists("/sys/firmware") {
+		rep.skip("sin /sys/firmware: no se puede determinar el modo de arranque")
+		return fw, nil
+	}
+	if !d.exists("/sys/firmware/efi") {
+		fw.BootMode = BootModeLegacy
+		return fw, nil
+	}
+	fw.BootMode = BootModeUEFI
+	fw.PlatformBits = d.readInt("/sys/firmware/efi/fw_platform_size")
+	fw.FirmwareRevision = d.uefiFirmwareRevision()
+
+	if !d.exists("/sys/firmware/efi/efivars") {
+		rep.note("efivarfs no está montado: sin Secure Boot ni entradas de arranque")
+		return fw, nil
+	}
+
+	if v, err := d.readEFIVar("SecureBoot"); err == nil && len(v) > 0 {
+		fw.SecureBootSupported = true
+		fw.SecureBoot = v[0] == 1
+	}
+	if v, err := d.readEFIVar("SetupMode"); err == nil && len(v) > 0 {
+		fw.SetupMode = v[0] == 1
+	}
+	if v, err := d.readEFIVar("BootCurrent"); err == nil && len(v) >= 2 {
+		fw.BootCurrent = fmt.Sprintf("%04X", binary.LittleEndian.Uint16(v))
+	}
+	if v, err := d.readEFIVar("BootOrder"); err == nil {
+		for i := 0; i+1 < len(v); i += 2 {
+			fw.BootOrder = append(fw.BootOrder, fmt.Sprintf("%04X", binary.LittleEndian.Uint16(v[i:])))
+		}
+	}
+
+	for _, name := range d.listDir("/sys/firmware/efi/efivars") {
+		id, ok := strings.CutPrefix(name, "Boot")
+		if !ok || !strings.HasSuffix(id, "-"+efiGlobalVariable) {
+			continue
+		}
+		id = strings.TrimSuffix(id, "-"+efiGlobalVariable)
+		if _, err := strconv.ParseUint(id, 16, 16); err != nil || len(id) != 4 {
+			continue // BootOrder, BootCurrent, BootNext...
+		}
+		data, err := d.readEFIVar("Boot" + id)
+		if err != nil {
+			if os.IsPermission(err) {
+				rep.note("sin permiso para leer Boot%s", id)
+			}
+			continue
+		}
+		entry, err := parseLoadOption(data)
+		if err != nil {
+			rep.note("Boot%s: %v", id, err)
+			continue
+		}
+		entry.ID = id
+		entry.Current = id == fw.BootCurrent
+		fw.BootEntries = append(fw.BootEntries, entry)
+	}
+
+	// Las entradas se presentan en el orden de arranque; las que no figuran
+	// en BootOrder, al final
+	position := make(map[string]int, len(fw.BootOrder))
+	for i, id := range fw.BootOrder {
+		position[id] = i
+	}
+	sort.SliceStable(fw.BootEntries, func(i, j int) bool {
+		pi, oki := position[fw.BootEntries[i].ID]
+		pj, okj := position[fw.BootEntries[j].ID]
+		if oki != okj {
+			return oki
+		}
+		if oki {
+			return pi < pj
+		}
+		return fw.BootEntries[i].ID < fw.BootEntries[j].ID
+	})
+
+	return fw, nil
+}
+
+// readEFIVar lee una variable global de UEFI desde efivarfs sin los 4 bytes
+// de atributos que la preceden
+func (d *Detector) readEFIVar(name string) ([]byte, error) {
+	data, err := os.ReadFile(d.path("/sys/firmware/efi/efivars/" + name + "-" + efiGlobalVariable))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 4 {
+		return nil, errors.New("variable EFI truncada")
+	}
+	return data[4:], nil
+}
+
+// uefiFirmwareRevision devuelve la versión del firmware del sistema según la
+// tabla ESRT (la que usan las actualizaciones por cápsula). Vacío si el
+// firmware no publica ESRT.
+func (d *Detector) uefiFirmwareRevision() string {
+	base := "/sys/firmware/efi/esrt/entries"
+	for _, entry := range d.listDir(base) {
+		if d.readInt(base+"/"+entry+"/fw_type") != esrtSystemFirmware {
+			continue
+		}
+		v, err := d.readString(base + "/" + entry + "/fw_version")
+		if err != nil {
+			continue
+		}
+		n, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return v
+		}
+		return fmt.Sprintf("0x%08X", n)
+	}
+	return ""
+}
+
+// parseLoadOption decodifica una EFI_LOAD_OPTION (variable Boot####):
+// atributos, longitud de la lista de rutas, descripción UTF-16 terminada en
+// NUL y la ruta de dispositivo
+func parseLoadOption(b []byte) (BootEntry, error) {
+	var entry BootEntry
+	if len(b) < 6 {
+		return entry, errors.New("entrada de arranque truncada")
+	}
+	attrs := binary.LittleEndian.Uint32(b)
+	pathLen := int(binary.LittleEndian.Uint16(b[4:]))
+	entry.Active = attrs&efiLoadOptionActive != 0
+
+	// Descripción: UTF-16LE hasta el NUL
+	end := 6
+	for end+1 < len(b) && (b[end] != 0 || b[end+1] != 0) {
+		end += 2
+	}
+	entry.Description = utf16String(b[6:end])
+	start := end + 2
+	if start+pathLen > len(b) {
+		return entry, errors.New("ruta de dispositivo truncada")
+	}
+	entry.DevicePath = devicePathString(b[start : start+pathLen])
+	return entry, nil
+}
+
+// devicePathString resume una ruta de dispositivo UEFI con la notación de
+// la especificación para los nodos que identifican el arranque: disco y
+// partición, archivo, red y arranque heredado (BBS)
+func devicePathString(b []byte) string {
+	nodes := make([]string, 0)
+	for len(b) >= 4 {
+		typ, sub := b[0], b[1]
+		n := int(binary.LittleEndian.Uint16(b[2:]))
+		if n < 4 || n > len(b) {
+			break
+		}
+		node := b[4:n]
+		b = b[n:]
+
+		switch {
+		case typ == 0x7F:
+			// Fin de la ruta (0xFF) o de una instancia (0x01)
+			if sub == 0xFF {
+				return strings.Join(nodes, "/")
+			}
+		case typ == 4 && sub == 1 && len(node) >= 38:
+			// Disco duro: número de partición y firma (GUID o MBR)
+			part := binary.LittleEndian.Uint32(node)
+			switch node[37] {
+			case 2:
+				nodes = append(nodes, fmt.Sprintf("HD(%d,GPT,%s)", part, formatGUID(node[20:36])))
+			case 1:
+				nodes = append(nodes, fmt.Sprintf("HD(%d,MBR,0x%08X)", part, binary.LittleEndian.Uint32(node[20:])))
+			default:
+				nodes = append(nodes, fmt.Sprintf("HD(%d)", part))
+			}
+		case typ == 4 && sub == 4:
+			nodes = append(nodes, utf16String(node))
+		case typ == 4 && (sub == 6 || sub == 7):
+			// Aplicación o volumen integrado en el firmware (Setup, shell...)
+			nodes = append(nodes, "Fv")
+		case typ == 3 && sub == 5:
+			nodes = append(nodes, "USB")
+		case typ == 3 && sub == 0x12:
+			nodes = append(nodes, "SATA")
+		case typ == 3 && sub == 0x17 && len(node) >= 4:
+			nodes = append(nodes, fmt.Sprintf("NVMe(%d)", binary.LittleEndian.Uint32(node)))
+		case typ == 3 && sub == 11 && len(node) >= 6:
+			nodes = append(nodes, "MAC("+net.HardwareAddr(node[:6]).String()+")")
+		case typ == 3 && sub == 12:
+			nodes = append(nodes, "IPv4")
+		case typ == 3 && sub == 13:
+			nodes = append(nodes, "IPv6")
+		case typ == 3 && sub == 24:
+			nodes = append(nodes, "URI("+string(node)+")")
+		case typ == 5:
+			nodes = append(nodes, "BBS")
+		}
+	}
+	return strings.Join(nodes, "/")
+}
@@ -1,8 +1,11 @@
 package hardware
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/Lexharden/hwscan/internal/utils"
 	"github.com/Lexharden/hwscan/internal/version"
@@ -28,6 +31,23 @@ func FormatConsole(info *HardwareInfo) string {
 	// Machine ID
 	fmt.Fprintln(&sb, "┌─ IDENTIFICACIÓN ─────────────────────────────────────────────┐")
 	fmt.Fprintf(&sb, "│ Machine ID: %s\n", info.MachineID)
+	if p := info.Platform; p.Virtual {
+		kinds := make([]string, 0, 2)
+		switch p.Hypervisor {
+		case "":
+		case HypervisorUnknown:
+			kinds = append(kinds, "máquina virtual, hipervisor desconocido")
+		default:
+			kinds = append(kinds, "máquina virtual "+p.Hypervisor)
+		}
+		if p.Container != "" {
+			kinds = append(kinds, "contenedor "+p.Container)
+		}
+		fmt.Fprintf(&sb, "│ Plataforma: VIRTUAL (%s)\n", strings.Join(kinds, ", "))
+		fmt.Fprintf(&sb, "│ Indicios:   %s\n", strings.Join(p.Evidence, "; "))
+	} else {
+		fmt.Fprintln(&sb, "│ Plataforma: física")
+	}
 	fmt.Fprintln(&sb, "└──────────────────────────────────────────────────────────────┘")
 	fmt.Fprintln(&sb)
 
@@ -40,9 +60,76 @@ func FormatConsole(info *HardwareInfo) string {
 	if info.CPU.CacheSize != "" {
 		fmt.Fprintf(&sb, "│ Caché:     %s\n", info.CPU.CacheSize)
 	}
+	if info.CPU.Family > 0 {
+		fmt.Fprintf(&sb, "│ Familia:   %d / modelo %d / stepping %d\n", info.CPU.Family, info.CPU.ModelNumber, info.CPU.Stepping)
+	}
+	if info.CPU.Microcode != "" {
+		fmt.Fprintf(&sb, "│ Microcód.: %s", info.CPU.Microcode)
+		if info.CPU.OldMicrocode {
+			fmt.Fprint(&sb, "  DESACTUALIZADO")
+		}
+		fmt.Fprintln(&sb)
+	}
+	if id := info.CPU.CPUID; id != nil {
+		check := "coincide con /proc/cpuinfo"
+		if len(id.Mismatches) > 0 {
+			check = fmt.Sprintf("%d diferencias con /proc/cpuinfo", len(id.Mismatches))
+		}
+		fmt.Fprintf(&sb, "│ CPUID:     %d características, %s\n", len(id.Features), check)
+		if len(id.HiddenFeatures) > 0 {
+			fmt.Fprintf(&sb, "│            ocultas por el kernel: %s\n", strings.Join(id.HiddenFeatures, " "))
+		}
+		if id.Hypervisor != "" {
+			hypervisor := id.Hypervisor
+			if hypervisor == HypervisorUnknown {
+				hypervisor = "desconocido"
+			}
+			fmt.Fprintf(&sb, "│ VM:        %s (%s)\n", hypervisor, id.HypervisorVendor)
+		}
+	}
+	if info.CPU.Packages > 0 {
+		fmt.Fprintf(&sb, "│ Topología: %d socket(s) / %d die(s) / %d núcleos / %d hilos\n",
+			info.CPU.Packages, info.CPU.Dies, info.CPU.Cores, info.CPU.Threads)
+	}
+	if info.CPU.Hybrid {
+		fmt.Fprintf(&sb, "│ Híbrido:   %s + %s\n",
+			coreTypeSummary(info.CPU.CoreList, CoreTypePerformance, "P"),
+			coreTypeSummary(info.CPU.CoreList, CoreTypeEfficiency, "E"))
+	} else if lo, hi := coreFrequencyRange(info.CPU.CoreList); hi > 0 {
+		fmt.Fprintf(&sb, "│ Rango:     %.2f - %.2f GHz\n", lo/1000, hi/1000)
+	}
+	if len(info.CPU.Caches) > 0 {
+		caches := make([]string, 0, len(info.CPU.Caches))
+		for _, c := range info.CPU.Caches {
+			caches = append(caches, fmt.Sprintf("%s %s ×%d", c.Name, formatCacheSize(c.SizeBytes), c.Instances))
+		}
+		fmt.Fprintf(&sb, "│ Cachés:    %s\n", strings.Join(caches, ", "))
+	}
+	if len(info.CPU.NUMANodes) > 1 {
+		for _, n := range info.CPU.NUMANodes {
+			fmt.Fprintf(&sb, "│ NUMA %d:    %.1f GB (CPU %s)\n", n.ID, float64(n.MemTotalBytes)/(1<<30), n.CPUs)
+		}
+	}
 	fmt.Fprintln(&sb, "└──────────────────────────────────────────────────────────────┘")
 	fmt.Fprintln(&sb)
 
+	// Vulnerabilidades del CPU
+	if len(info.CPU.Vulnerabilities) > 0 {
+		counts := make(map[string]int)
+		for _, v := range info.CPU.Vulnerabilities {
+			counts[v.Status]++
+		}
+		fmt.Fprintln(&sb, "┌─ SEGURIDAD CPU ──────────────────────────────────────────────┐")
+		fmt.Fprintf(&sb, "│ %d vulnerables, %d mitigadas, %d no afectan\n",
+			counts[VulnVulnerable], counts[VulnMitigated], counts[VulnNotAffected])
+		for _, v := range info.CPU.Vulnerabilities {
+			line := fmt.Sprintf("│ %-26s %-13s %s", v.Name, v.Status, v.Detail)
+			fmt.Fprintln(&sb, strings.TrimRight(line, " "))
+		}
+		fmt.Fprintln(&sb, "└──────────────────────────────────────────────────────────────┘")
+		fmt.Fprintln(&sb)
+	}
+
 	// Memoria
 	fmt.Fprintln(&sb, "┌─ MEMORIA RAM ────────────────────────────────────────────────┐")
 	fmt.Fprintf(&sb, "│ Total:     %.2f GB (%.0f bytes)\n",
@@ -73,6 +160,44 @@ func FormatConsole(info *HardwareInfo) string {
 	fmt.Fprintln(&sb, "└──────────────────────────────────────────────────────────────┘")
 	fmt.Fprintln(&sb)
 
+	// Sistema (producto OEM, service tag y chasis)
+	if s := info.System; s.Manufacturer != "" || s.Product != "" || s.ChassisType != "" {
+		fmt.Fprintln(&sb, "┌─ SISTEMA ────────────────────────────────────────────────────┐")
+		fmt.Fprintf(&sb, "│ Fabricante: %s\n", s.Manufacturer)
+		product := s.Product
+		if s.Version != "" {
+			product += " (" + s.Version + ")"
+		}
+		fmt.Fprintf(&sb, "│ Producto:   %s\n", product)
+		if s.Family != "" {
+			fmt.Fprintf(&sb, "│ Familia:    %s\n", s.Family)
+		}
+		if s.SKU != "" {
+			fmt.Fprintf(&sb, "│ SKU:        %s\n", s.SKU)
+		}
+		if s.SerialNumber != "" {
+			fmt.Fprintf(&sb, "│ Serie:      %s\n", s.SerialNumber)
+		}
+		if s.UUID != "" {
+			fmt.Fprintf(&sb, "│ UUID:       %s\n", s.UUID)
+		}
+		if s.ChassisType != "" {
+			chassis := s.ChassisType
+			if s.ChassisVendor != "" && s.ChassisVendor != s.Manufacturer {
+				chassis += " (" + s.ChassisVendor + ")"
+			}
+			fmt.Fprintf(&sb, "│ Chasis:     %s\n", chassis)
+		}
+		if s.ChassisSerial != "" && s.ChassisSerial != s.SerialNumber {
+			fmt.Fprintf(&sb, "│ Chasis S/N: %s\n", s.ChassisSerial)
+		}
+		if s.ChassisAssetTag != "" {
+			fmt.Fprintf(&sb, "│ Inventario: %s\n", s.ChassisAssetTag)
+		}
+		fmt.Fprintln(&sb, "└──────────────────────────────────────────────────────────────┘")
+		fmt.Fprintln(&sb)
+	}
+
 	// Placa Madre
 	fmt.Fprintln(&sb, "┌─ PLACA MADRE ────────────────────────────────────────────────┐")
 	fmt.Fprintf(&sb, "│ Fabricante: %s\n", info.Motherboard.Manufacturer)
@@ -92,15 +217,160 @@ func FormatConsole(info *HardwareInfo) string {
 	fmt.Fprintln(&sb, "└──────────────────────────────────────────────────────────────┘")
 	fmt.Fprintln(&sb)
 
+	// Firmware y arranque
+	if fw := info.Firmware; fw.BootMode != "" {
+		fmt.Fprintln(&sb, "┌─ FIRMWARE / ARRANQUE ────────────────────────────────────────┐")
+		mode := fw.BootMode
+		if fw.PlatformBits > 0 {
+			mode += fmt.Sprintf(" (%d bits)", fw.PlatformBits)
+		}
+		fmt.Fprintf(&sb, "│ Arranque:    %s\n", mode)
+		if fw.FirmwareRevision != "" {
+			fmt.Fprintf(&sb, "│ Revisión:    %s\n", fw.FirmwareRevision)
+		}
+		if fw.BootMode == BootModeUEFI {
+			secure := "no soportado"
+			if fw.SecureBootSupported {
+				secure = "DESACTIVADO"
+				if fw.SecureBoot {
+					secure = "activado"
+				}
+			}
+			if fw.SetupMode {
+				secure += " (Setup Mode)"
+			}
+			fmt.Fprintf(&sb, "│ Secure Boot: %s\n", secure)
+		}
+		for _, e := range fw.BootEntries {
+			mark := " "
+			if e.Current {
+				mark = "*"
+			}
+			fmt.Fprintf(&sb, "│ %s Boot%s %s", mark, e.ID, e.Description)
+			if !e.Active {
+				fmt.Fprint(&sb, " (inactiva)")
+			}
+			fmt.Fprintln(&sb)
+			if e.DevicePath != "" {
+				fmt.Fprintf(&sb, "│     %s\n", e.DevicePath)
+			}
+		}
+		fmt.Fprintln(&sb, "└──────────────────────────────────────────────────────────────┘")
+		fmt.Fprintln(&sb)
+	}
+
+	// TPM
+	if tpm := info.TPM; tpm.Present {
+		fmt.Fprintln(&sb, "┌─ TPM ────────────────────────────────────────────────────────┐")
+		fmt.Fprintf(&sb, "│ Versión:     %s (%s)\n", valueOr(tpm.Version, "desconocida"), tpm.Device)
+		if tpm.Manufacturer != "" {
+			maker := tpm.Manufacturer
+			if tpm.ManufacturerID != tpm.Manufacturer {
+				maker += " (" + tpm.ManufacturerID + ")"
+			}
+			if tpm.VendorString != "" {
+				maker += " " + tpm.VendorString
+			}
+			fmt.Fprintf(&sb, "│ Fabricante:  %s\n", maker)
+		}
+		if tpm.FirmwareVersion != "" {
+			fmt.Fprintf(&sb, "│ Firmware:    %s\n", tpm.FirmwareVersion)
+		}
+		state := make([]string, 0, 2)
+		switch {
+		case tpm.Enabled == nil:
+		case *tpm.Enabled:
+			state = append(state, "habilitado")
+		default:
+			state = append(state, "DESHABILITADO")
+		}
+		switch {
+		case tpm.Owned == nil:
+		case *tpm.Owned:
+			state = append(state, "con propietario")
+		default:
+			state = append(state, "sin propietario")
+		}
+		if len(state) > 0 {
+			fmt.Fprintf(&sb, "│ Estado:      %s\n", strings.Join(state, ", "))
+		}
+		if len(tpm.Banks) > 0 {
+			fmt.Fprintf(&sb, "│ Bancos PCR:  %s\n", strings.Join(tpm.Banks, ", "))
+		}
+		// Solo los PCR extendidos: los que siguen a cero (o a FF) no tienen
+		// mediciones
+		header := false
+		for _, pcr := range tpm.PCRs {
+			if strings.Trim(pcr.Value, "0") == "" || strings.Trim(pcr.Value, "F") == "" {
+				continue
+			}
+			if !header {
+				fmt.Fprintln(&sb, "│ PCR SHA-256:")
+				header = true
+			}
+			fmt.Fprintf(&sb, "│   %2d %s\n", pcr.Index, pcr.Value)
+		}
+		fmt.Fprintln(&sb, "└──────────────────────────────────────────────────────────────┘")
+		fmt.Fprintln(&sb)
+	}
+
 	// GPU
 	if len(info.GPU) > 0 {
 		fmt.Fprintln(&sb, "┌─ GPU ────────────────────────────────────────────────────────┐")
 		for i, gpu := range info.GPU {
-			fmt.Fprintf(&sb, "│ [%d] %s %s\n", i+1, gpu.Vendor, gpu.Model)
-			fmt.Fprintf(&sb, "│     PCI: %s\n", gpu.PCIAddress)
+			fmt.Fprintf(&sb, "│ [%d] %s %s", i+1, gpu.Vendor, gpu.Model)
+			if gpu.BootVGA {
+				fmt.Fprint(&sb, " (arranque)")
+			}
+			fmt.Fprintln(&sb)
+
+			fmt.Fprintf(&sb, "│     PCI: %s", gpu.PCIAddress)
+			if gpu.Driver != "" {
+				fmt.Fprintf(&sb, " | Driver: %s", gpu.Driver)
+			}
+			if gpu.PowerState != "" {
+				fmt.Fprintf(&sb, " | %s", gpu.PowerState)
+			}
+			fmt.Fprintln(&sb)
+
+			if gpu.LinkWidth > 0 {
+				fmt.Fprintf(&sb, "│     Enlace: %s", pcieLink(gpu.LinkSpeed, gpu.LinkWidth))
+				if gpu.MaxLinkWidth > 0 && (gpu.MaxLinkSpeed != gpu.LinkSpeed || gpu.MaxLinkWidth != gpu.LinkWidth) {
+					fmt.Fprintf(&sb, " (máx. %s)", pcieLink(gpu.MaxLinkSpeed, gpu.MaxLinkWidth))
+				}
+				fmt.Fprintln(&sb)
+			}
+			if gpu.DRMCard != "" || gpu.DRMRender != "" {
+				nodes := make([]string, 0, 2)
+				for _, node := range []string{gpu.DRMCard, gpu.DRMRender} {
+					if node != "" {
+						nodes = append(nodes, node)
+					}
+				}
+				fmt.Fprintf(&sb, "│     DRM: %s\n", strings.Join(nodes, ", "))
+			}
 
 			if gpu.MemorySize != "" {
-				fmt.Fprintf(&sb, "│     VRAM: %s\n", gpu.MemorySize)
+				fmt.Fprintf(&sb, "│     VRAM: %s (%s)\n", gpu.MemorySize, gpu.VRAMSource)
+			}
+			if gpu.VBIOSVersion != "" {
+				fmt.Fprintf(&sb, "│     VBIOS: %s\n", gpu.VBIOSVersion)
+			}
+			if gpu.BusyPercent >= 0 || gpu.CurrentMHz > 0 {
+				fmt.Fprint(&sb, "│     ")
+				if gpu.BusyPercent >= 0 {
+					fmt.Fprintf(&sb, "Uso: %d%%", gpu.BusyPercent)
+					if gpu.CurrentMHz > 0 {
+						fmt.Fprint(&sb, " | ")
+					}
+				}
+				if gpu.CurrentMHz > 0 {
+					fmt.Fprintf(&sb, "Reloj: %d MHz", gpu.CurrentMHz)
+					if gpu.MaxMHz > 0 {
+						fmt.Fprintf(&sb, " (%d-%d MHz)", gpu.MinMHz, gpu.MaxMHz)
+					}
+				}
+				fmt.Fprintln(&sb)
 			}
 
 			if i < len(info.GPU)-1 {
@@ -111,6 +381,80 @@ func FormatConsole(info *HardwareInfo) string {
 		fmt.Fprintln(&sb)
 	}
 
+	// Monitores
+	if len(info.Displays) > 0 {
+		fmt.Fprintln(&sb, "┌─ MONITORES ──────────────────────────────────────────────────┐")
+		for i, m := range info.Displays {
+			fmt.Fprintf(&sb, "│ [%d] %s", i+1, m.Connector)
+			if m.Internal {
+				fmt.Fprint(&sb, " (integrado)")
+			}
+			fmt.Fprintf(&sb, " -> %s\n", displayGPU(info.GPU, m))
+
+			if m.ManufacturerID != "" {
+				maker := m.ManufacturerID
+				if m.Manufacturer != "" {
+					maker = m.Manufacturer + " (" + m.ManufacturerID + ")"
+				}
+				fmt.Fprintf(&sb, "│     %s %s | Producto: %s", maker, m.Model, m.ProductCode)
+				if m.SerialNumber != "" {
+					fmt.Fprintf(&sb, " | Serie: %s", m.SerialNumber)
+				}
+				fmt.Fprintln(&sb)
+			}
+			if m.NativeWidth > 0 {
+				fmt.Fprintf(&sb, "│     Nativa: %dx%d @ %.2f Hz", m.NativeWidth, m.NativeHeight, m.NativeRefreshHz)
+				if m.MaxRefreshHz > 0 {
+					fmt.Fprintf(&sb, " (rango %d-%d Hz)", m.MinRefreshHz, m.MaxRefreshHz)
+				}
+				fmt.Fprintln(&sb)
+			}
+			if m.WidthMM > 0 {
+				fmt.Fprintf(&sb, "│     Tamaño: %.1f x %.1f cm (%.1f\")\n", float64(m.WidthMM)/10, float64(m.HeightMM)/10, m.DiagonalInches)
+			}
+			if m.Year > 0 {
+				made := fmt.Sprintf("Fabricado: %d", m.Year)
+				switch {
+				case m.ModelYear:
+					made = fmt.Sprintf("Modelo: %d", m.Year)
+				case m.Week > 0:
+					made = fmt.Sprintf("Fabricado: semana %d/%d", m.Week, m.Year)
+				}
+				fmt.Fprintf(&sb, "│     %s | EDID %s", made, m.EDIDVersion)
+				if m.Interface != "" {
+					fmt.Fprintf(&sb, " | %s", m.Interface)
+				}
+				if m.BitDepth > 0 {
+					fmt.Fprintf(&sb, " %d bits", m.BitDepth)
+				}
+				fmt.Fprintln(&sb)
+			}
+			if len(m.Features) > 0 {
+				fmt.Fprintf(&sb, "│     CTA-861: %s\n", strings.Join(m.Features, ", "))
+			}
+
+			if i < len(info.Displays)-1 {
+				fmt.Fprintln(&sb, "│")
+			}
+		}
+		fmt.Fprintln(&sb, "└──────────────────────────────────────────────────────────────┘")
+		fmt.Fprintln(&sb)
+	}
+
+	// Dispositivos PCI
+	if len(info.PCI) > 0 {
+		fmt.Fprintln(&sb, "┌─ DISPOSITIVOS PCI ───────────────────────────────────────────┐")
+		for _, dev := range info.PCI {
+			fmt.Fprintf(&sb, "│ %s %s: %s", dev.Address, dev.ClassName, pciDisplayName(dev))
+			if dev.Driver != "" {
+				fmt.Fprintf(&sb, " [%s]", dev.Driver)
+			}
+			fmt.Fprintln(&sb)
+		}
+		fmt.Fprintln(&sb, "└──────────────────────────────────────────────────────────────┘")
+		fmt.Fprintln(&sb)
+	}
+
 	// Discos
 	if len(info.Disks) > 0 {
 		fmt.Fprintln(&sb, "┌─ ALMACENAMIENTO ────────────────────────────────────────────────┐")
@@ -126,12 +470,93 @@ func FormatConsole(info *HardwareInfo) string {
 			}
 			fmt.Fprintln(&sb)
 
+			capacity := fmt.Sprintf("%.1f GB", disk.SizeGB)
+			if disk.SizeBytes == 0 {
+				capacity = "sin medio"
+			}
 			fmt.Fprintf(&sb,
-				"│     Capacidad: %.1f GB | Tipo: %s | Dev: /dev/%s\n",
-				disk.SizeGB,
+				"│     Capacidad: %s | Tipo: %s | Dev: /dev/%s",
+				capacity,
 				disk.Type,
 				disk.Name,
 			)
+			if disk.Removable {
+				fmt.Fprint(&sb, " | Extraíble")
+			}
+			fmt.Fprintln(&sb)
+
+			if disk.Serial != "" || disk.Transport != "" {
+				fmt.Fprintf(&sb, "│     Serie: %s | Bus: %s", valueOr(disk.Serial, "—"), valueOr(disk.Transport, "—"))
+				if disk.Firmware != "" {
+					fmt.Fprintf(&sb, " | Firmware: %s", disk.Firmware)
+				}
+				if disk.ManufactureDate != "" {
+					fmt.Fprintf(&sb, " | Fabricado: %s", disk.ManufactureDate)
+				}
+				fmt.Fprintln(&sb)
+			}
+			if o := disk.Optical; o != nil {
+				fmt.Fprint(&sb, "│     Óptica:")
+				if o.SpeedX > 0 {
+					fmt.Fprintf(&sb, " %dx |", o.SpeedX)
+				}
+				fmt.Fprintf(&sb, " %s", valueOr(strings.Join(o.Capabilities, ", "), "capacidades desconocidas"))
+				if o.Status != "" {
+					fmt.Fprintf(&sb, " | Estado: %s", o.Status)
+				}
+				fmt.Fprintln(&sb)
+			}
+			if disk.WWN != "" {
+				fmt.Fprintf(&sb, "│     WWN: %s\n", disk.WWN)
+			}
+			if disk.LogicalBlockSize > 0 {
+				fmt.Fprintf(&sb, "│     Bloques: %d/%d bytes (lógico/físico)", disk.LogicalBlockSize, disk.PhysicalBlockSize)
+				if disk.Discard {
+					fmt.Fprint(&sb, " | TRIM")
+				}
+				if disk.NVMeNamespaceID > 0 {
+					fmt.Fprintf(&sb, " | NSID %d, CNTLID %d", disk.NVMeNamespaceID, disk.NVMeControllerID)
+				}
+				fmt.Fprintln(&sb)
+			}
+
+			if h := disk.Health; h != nil {
+				fmt.Fprintf(&sb, "│     Salud: %s", healthVerdict(h))
+				if h.PowerOnHours > 0 {
+					fmt.Fprintf(&sb, " | %d h", h.PowerOnHours)
+				}
+				if h.TemperatureC > 0 {
+					fmt.Fprintf(&sb, " | %d °C", h.TemperatureC)
+				}
+				if h.PercentageUsed >= 0 {
+					fmt.Fprintf(&sb, " | Desgaste: %d%%", h.PercentageUsed)
+				}
+				fmt.Fprintln(&sb)
+				if h.Source == "ata" {
+					fmt.Fprintf(&sb, "│     Reasignados: %d | Pendientes: %d | No corregibles: %d\n",
+						h.ReallocatedSectors, h.PendingSectors, h.UncorrectableErrors)
+				} else {
+					fmt.Fprintf(&sb, "│     Errores de medio: %d | Reserva: %d%% | Apagados sin aviso: %d\n",
+						h.MediaErrors, h.AvailableSpare, h.UnsafeShutdowns)
+				}
+			}
+
+			if len(disk.Contents) > 0 {
+				fmt.Fprintf(&sb, "│     Contenido: %s\n", strings.Join(disk.Contents, ", "))
+			}
+			if disk.Filesystem.Type != "" {
+				fmt.Fprintf(&sb, "│     Sin particiones: %s\n", filesystemSummary(disk.Filesystem))
+			}
+			if len(disk.Partitions) > 0 {
+				fmt.Fprintf(&sb, "│     Particiones (%s):\n", valueOr(strings.ToUpper(disk.PartitionTable), "sysfs"))
+				for _, p := range disk.Partitions {
+					fmt.Fprintf(&sb, "│       %-11s %9s  %-20s %s\n",
+						valueOr(p.Name, fmt.Sprint(p.Number)),
+						formatPartitionSize(p.SizeBytes),
+						valueOr(p.TypeName, p.Type),
+						filesystemSummary(p.Filesystem))
+				}
+			}
 
 			if i < len(info.Disks)-1 {
 				fmt.Fprintln(&sb, "│")
@@ -141,6 +566,222 @@ func FormatConsole(info *HardwareInfo) string {
 		fmt.Fprintln(&sb)
 	}
 
+	// RAID y device-mapper
+	if len(info.Storage.RAID) > 0 || len(info.Storage.Mappings) > 0 {
+		fmt.Fprintln(&sb, boxHeader("RAID / LVM"))
+		for _, md := range info.Storage.RAID {
+			level := strings.TrimSpace(md.Level + " " + md.Status)
+			fmt.Fprintf(&sb, "│ %s: %s | %s | %s\n",
+				md.Name, level, valueOr(md.State, "—"), formatPartitionSize(md.SizeBytes))
+			if md.Degraded > 0 {
+				fmt.Fprintf(&sb, "│     DEGRADADO: faltan %d de %d miembros\n", md.Degraded, md.RaidDisks)
+			}
+			if md.SyncProgress >= 0 {
+				fmt.Fprintf(&sb, "│     %s: %.1f%%", md.SyncAction, md.SyncProgress)
+				if md.SyncFinish != "" {
+					fmt.Fprintf(&sb, " (quedan %s)", md.SyncFinish)
+				}
+				fmt.Fprintln(&sb)
+			}
+			members := make([]string, 0, len(md.Members))
+			for _, m := range md.Members {
+				members = append(members, fmt.Sprintf("%s (%s)", m.Device, valueOr(m.State, "?")))
+			}
+			fmt.Fprintf(&sb, "│     Miembros: %s\n", valueOr(strings.Join(members, ", "), "—"))
+			fmt.Fprintf(&sb, "│     Discos: %s\n", valueOr(strings.Join(md.Disks, ", "), "—"))
+		}
+		for _, m := range info.Storage.Mappings {
+			fmt.Fprintf(&sb, "│ %s: %s (%s) | %s\n", m.Name, valueOr(m.MapName, "—"), m.Kind, formatPartitionSize(m.SizeBytes))
+			fmt.Fprintf(&sb, "│     Sobre: %s | Discos: %s\n",
+				valueOr(strings.Join(m.Slaves, ", "), "—"), valueOr(strings.Join(m.Disks, ", "), "—"))
+		}
+		fmt.Fprintln(&sb, "└──────────────────────────────────────────────────────────────┘")
+		fmt.Fprintln(&sb)
+	}
+
+	// Red
+	if len(info.Network) > 0 {
+		fmt.Fprintln(&sb, "┌─ RED ────────────────────────────────────────────────────────┐")
+		for i, iface := range info.Network {
+			kind := "Ethernet"
+			if iface.Wireless {
+				kind = "Wi-Fi"
+			}
+			if iface.Virtual {
+				kind = "virtual"
+			}
+			fmt.Fprintf(&sb, "│ [%d] %s (%s)", i+1, iface.Name, kind)
+			if iface.Model != "" {
+				fmt.Fprintf(&sb, " %s", iface.Model)
+			}
+			fmt.Fprintln(&sb)
+
+			fmt.Fprintf(&sb, "│     MAC: %s", iface.MAC)
+			if iface.PermanentMAC != "" && !strings.EqualFold(iface.PermanentMAC, iface.MAC) {
+				fmt.Fprintf(&sb, " (de fábrica: %s)", iface.PermanentMAC)
+			}
+			fmt.Fprintf(&sb, " | MTU: %d\n", iface.MTU)
+
+			link := "sin enlace"
+			if iface.Carrier {
+				link = "con enlace"
+				if iface.SpeedMbps > 0 {
+					link += fmt.Sprintf(" %d Mb/s", iface.SpeedMbps)
+				}
+				if iface.Duplex != "" {
+					link += " " + iface.Duplex + "-duplex"
+				}
+			}
+			fmt.Fprintf(&sb, "│     Enlace: %s", link)
+			if iface.Driver != "" {
+				fmt.Fprintf(&sb, " | Driver: %s", iface.Driver)
+			}
+			if iface.FirmwareVersion != "" {
+				fmt.Fprintf(&sb, " | Firmware: %s", iface.FirmwareVersion)
+			}
+			fmt.Fprintln(&sb)
+
+			if iface.ParentAddress != "" {
+				fmt.Fprintf(&sb, "│     %s: %s\n", strings.ToUpper(iface.Bus), iface.ParentAddress)
+			}
+
+			if i < len(info.Network)-1 {
+				fmt.Fprintln(&sb, "│")
+			}
+		}
+		fmt.Fprintln(&sb, "└──────────────────────────────────────────────────────────────┘")
+		fmt.Fprintln(&sb)
+	}
+
+	// USB
+	if len(info.USB) > 0 {
+		fmt.Fprintln(&sb, "┌─ USB ────────────────────────────────────────────────────────┐")
+		for _, root := range info.USB {
+			fmt.Fprintf(&sb, "│ Bus %d: %s\n", root.Bus, usbSummary(root))
+			writeUSBTree(&sb, root.Children, "")
+		}
+		fmt.Fprintln(&sb, "└──────────────────────────────────────────────────────────────┘")
+		fmt.Fprintln(&sb)
+	}
+
+	// Energía
+	if len(info.Power.Batteries) > 0 || len(info.Power.Adapters) > 0 {
+		fmt.Fprintln(&sb, "┌─ ENERGÍA ────────────────────────────────────────────────────┐")
+		if len(info.Power.Adapters) > 0 {
+			ac := "desconectada"
+			if info.Power.OnAC {
+				ac = "conectada"
+			}
+			fmt.Fprintf(&sb, "│ Corriente:    %s\n", ac)
+		}
+		for _, bat := range info.Power.Batteries {
+			fmt.Fprintf(&sb, "│ %s: %s\n", bat.Name, valueOr(strings.TrimSpace(bat.Manufacturer+" "+bat.Model), "?"))
+			if !bat.Present {
+				fmt.Fprintln(&sb, "│   No insertada")
+				continue
+			}
+			if bat.HealthPercent >= 0 {
+				fmt.Fprintf(&sb, "│   Salud:      %.0f%% (desgaste %.0f%%)", bat.HealthPercent, bat.WearPercent)
+				if bat.BelowThreshold {
+					fmt.Fprintf(&sb, "  BAJO UMBRAL (<%.0f%%)", info.Power.HealthThreshold)
+				}
+				fmt.Fprintln(&sb)
+				fmt.Fprintf(&sb, "│   Capacidad:  %s de %s de diseño\n",
+					formatCapacity(bat.FullCapacity, bat.CapacityUnit), formatCapacity(bat.DesignCapacity, bat.CapacityUnit))
+			} else {
+				fmt.Fprintln(&sb, "│   Salud:      DESCONOCIDA")
+			}
+			if bat.CycleCount >= 0 {
+				fmt.Fprintf(&sb, "│   Ciclos:     %d\n", bat.CycleCount)
+			}
+			if bat.ChargePercent >= 0 {
+				fmt.Fprintf(&sb, "│   Carga:      %d%% (%s)\n", bat.ChargePercent, valueOr(bat.Status, "?"))
+			} else if bat.CapacityLevel != "" {
+				fmt.Fprintf(&sb, "│   Carga:      %s (%s)\n", bat.CapacityLevel, valueOr(bat.Status, "?"))
+			}
+			if bat.Technology != "" {
+				fmt.Fprintf(&sb, "│   Química:    %s\n", bat.Technology)
+			}
+			if bat.Serial != "" {
+				fmt.Fprintf(&sb, "│   Serie:      %s\n", bat.Serial)
+			}
+		}
+		fmt.Fprintln(&sb, "└──────────────────────────────────────────────────────────────┘")
+		fmt.Fprintln(&sb)
+	}
+
+	// Sensores
+	if len(info.Sensors) > 0 {
+		fmt.Fprintln(&sb, "┌─ SENSORES ───────────────────────────────────────────────────┐")
+		for i, chip := range info.Sensors {
+			fmt.Fprintf(&sb, "│ %s", chip.Name)
+			if chip.Parent != "" {
+				fmt.Fprintf(&sb, " (%s)", chip.Parent)
+			}
+			fmt.Fprintln(&sb)
+			for _, r := range chip.Readings {
+				fmt.Fprintf(&sb, "│   %-20s %s%s\n", r.Label, formatSensorValue(r.Value, r.Unit), sensorLimits(r))
+			}
+			if i < len(info.Sensors)-1 {
+				fmt.Fprintln(&sb, "│")
+			}
+		}
+		fmt.Fprintln(&sb, "└──────────────────────────────────────────────────────────────┘")
+		fmt.Fprintln(&sb)
+	}
+
+	// Serie de sensores (-sensor-samples)
+	if len(info.SensorSeries) > 0 {
+		fmt.Fprintln(&sb, boxHeader(fmt.Sprintf("SENSORES (%d MUESTRAS)", len(info.SensorSeries[0].Values))))
+		fmt.Fprintf(&sb, "│ %-28s %12s %12s %12s\n", "", "mín", "media", "máx")
+		for _, t := range info.SensorSeries {
+			fmt.Fprintf(&sb, "│ %-28s %12s %12s %12s",
+				t.Chip+" "+t.Label,
+				formatSensorValue(t.Min, t.Unit), formatSensorValue(t.Avg, t.Unit), formatSensorValue(t.Max, t.Unit))
+			if t.Alarm {
+				fmt.Fprint(&sb, "  ALARMA")
+			}
+			fmt.Fprintln(&sb)
+		}
+		fmt.Fprintln(&sb, "└──────────────────────────────────────────────────────────────┘")
+		fmt.Fprintln(&sb)
+	}
+
+	// Secciones de plugins, en orden alfabético por nombre
+	names := make([]string, 0, len(info.Sections))
+	for name := range info.Sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		section := info.Sections[name]
+		fmt.Fprintln(&sb, boxHeader(strings.ToUpper(section.Title)))
+		for _, line := range sectionLines(section.Data) {
+			fmt.Fprintf(&sb, "│ %s\n", line)
+		}
+		fmt.Fprintln(&sb, "└──────────────────────────────────────────────────────────────┘")
+		fmt.Fprintln(&sb)
+	}
+
+	// Diagnóstico: solo los detectores que no terminaron correctamente
+	var issues []DetectorDiagnostic
+	for _, diag := range info.Diagnostics {
+		if diag.Status != StatusOK {
+			issues = append(issues, diag)
+		}
+	}
+	if len(issues) > 0 {
+		fmt.Fprintln(&sb, "┌─ DIAGNÓSTICO ────────────────────────────────────────────────┐")
+		for _, diag := range issues {
+			fmt.Fprintf(&sb, "│ %-12s %-8s (%s, %d ms)\n", diag.Name, diag.Status, diag.Source, diag.DurationMS)
+			for _, msg := range diag.Messages {
+				fmt.Fprintf(&sb, "│     %s\n", msg)
+			}
+		}
+		fmt.Fprintln(&sb, "└──────────────────────────────────────────────────────────────┘")
+		fmt.Fprintln(&sb)
+	}
+
 	// Footer
 	fmt.Fprintln(&sb, "═══════════════════════════════════════════════════════════════")
 	fmt.Fprintf(&sb, "Interfaz Web: http://%s:8080\n", utils.GetLocalIP())
@@ -149,3 +790,199 @@ func FormatConsole(info *HardwareInfo) string {
 
 	return sb.String()
 }
+
+// boxHeader genera la línea superior de una sección con el título dado,
+// con el mismo ancho que el resto de recuadros
+func boxHeader(title string) string {
+	dashes := 64 - 5 - utf8.RuneCountInString(title)
+	if dashes < 1 {
+		dashes = 1
+	}
+	return "┌─ " + title + " " + strings.Repeat("─", dashes) + "┐"
+}
+
+// sectionLines devuelve las líneas de consola de los datos de un plugin:
+// las de ConsoleSection si lo implementa o, si no, su JSON indentado
+func sectionLines(data any) []string {
+	if cs, ok := data.(ConsoleSection); ok {
+		return cs.ConsoleLines()
+	}
+	out, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return []string{fmt.Sprintf("(error serializando sección: %v)", err)}
+	}
+	return strings.Split(string(out), "\n")
+}
+
+// writeUSBTree escribe los dispositivos USB conectados a un hub como ramas
+// de un árbol, con prefix como sangría acumulada de los niveles superiores
+func writeUSBTree(sb *strings.Builder, devices []USBDevice, prefix string) {
+	for i, dev := range devices {
+		branch, indent := "├─ ", "│  "
+		if i == len(devices)-1 {
+			branch, indent = "└─ ", "   "
+		}
+		fmt.Fprintf(sb, "│ %s%s%s %s\n", prefix, branch, dev.Path, usbSummary(dev))
+		writeUSBTree(sb, dev.Children, prefix+indent)
+	}
+}
+
+// usbSummary resume un dispositivo USB en una línea: nombre, IDs, clase,
+// drivers, velocidad y consumo
+func usbSummary(dev USBDevice) string {
+	parts := []string{fmt.Sprintf("%s [%s:%s]", usbDisplayName(dev), dev.VendorID, dev.ProductID)}
+	if dev.Class != "" {
+		parts = append(parts, dev.Class)
+	}
+	if len(dev.Drivers) > 0 {
+		parts = append(parts, strings.Join(dev.Drivers, ","))
+	}
+	if dev.SpeedMbps > 0 {
+		parts = append(parts, fmt.Sprintf("%g Mb/s", dev.SpeedMbps))
+	}
+	if dev.MaxPower != "" && dev.MaxPower != "0mA" {
+		parts = append(parts, dev.MaxPower)
+	}
+	if dev.Serial != "" && !dev.IsHub {
+		parts = append(parts, "S/N "+dev.Serial)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// filesystemSummary resume un sistema de archivos en una línea: tipo,
+// etiqueta entre comillas y UUID
+func filesystemSummary(fs FilesystemInfo) string {
+	parts := make([]string, 0, 3)
+	if fs.Type != "" {
+		parts = append(parts, fs.Type)
+	}
+	if fs.Label != "" {
+		parts = append(parts, fmt.Sprintf("%q", fs.Label))
+	}
+	if fs.UUID != "" {
+		parts = append(parts, fs.UUID)
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatPartitionSize formatea el tamaño de una partición ("931.5 GB", "512 MB")
+func formatPartitionSize(b uint64) string {
+	if gb := float64(b) / (1 << 30); gb >= 1 {
+		return fmt.Sprintf("%.1f GB", gb)
+	}
+	return fmt.Sprintf("%.0f MB", float64(b)/(1<<20))
+}
+
+// formatSensorValue formatea una lectura con la precisión habitual de su
+// unidad ("52.0 °C", "1200 RPM", "1.225 V")
+func formatSensorValue(v float64, unit string) string {
+	switch unit {
+	case "RPM":
+		return fmt.Sprintf("%.0f %s", v, unit)
+	case "V", "A":
+		return fmt.Sprintf("%.3f %s", v, unit)
+	}
+	return fmt.Sprintf("%.1f %s", v, unit)
+}
+
+// coreTypeSummary resume los núcleos de un tipo de un procesador híbrido
+// ("8 P hasta 5.40 GHz")
+func coreTypeSummary(cores []CPUCore, typ, short string) string {
+	n, top := 0, 0.0
+	for _, c := range cores {
+		if c.Type == typ {
+			n++
+			top = max(top, c.MaxMHz)
+		}
+	}
+	if top == 0 {
+		return fmt.Sprintf("%d %s", n, short)
+	}
+	return fmt.Sprintf("%d %s hasta %.2f GHz", n, short, top/1000)
+}
+
+// coreFrequencyRange devuelve la menor frecuencia mínima y la mayor máxima
+// de los núcleos en MHz (0 si cpufreq no está disponible)
+func coreFrequencyRange(cores []CPUCore) (lo, hi float64) {
+	for _, c := range cores {
+		if c.MinMHz > 0 && (lo == 0 || c.MinMHz < lo) {
+			lo = c.MinMHz
+		}
+		hi = max(hi, c.MaxMHz)
+	}
+	return lo, hi
+}
+
+// formatCapacity formatea una capacidad de batería ("41.2 Wh", "3300 mAh")
+func formatCapacity(v float64, unit string) string {
+	if unit == CapacityMAh {
+		return fmt.Sprintf("%.0f %s", v, unit)
+	}
+	return fmt.Sprintf("%.1f %s", v, unit)
+}
+
+// sensorLimits resume los umbrales y la alarma de un sensor
+// (" (máx 80.0, crít 100.0)  ALARMA")
+func sensorLimits(r SensorReading) string {
+	limits := make([]string, 0, 3)
+	if r.Min != 0 {
+		limits = append(limits, fmt.Sprintf("mín %g", r.Min))
+	}
+	if r.Max != 0 {
+		limits = append(limits, fmt.Sprintf("máx %g", r.Max))
+	}
+	if r.Crit != 0 {
+		limits = append(limits, fmt.Sprintf("crít %g", r.Crit))
+	}
+	s := ""
+	if len(limits) > 0 {
+		s = " (" + strings.Join(limits, ", ") + ")"
+	}
+	if r.Alarm {
+		s += "  ALARMA"
+	}
+	return s
+}
+
+// healthVerdict devuelve el veredicto SMART para mostrar, "DESCONOCIDO" si el
+// disco no lo informa
+func healthVerdict(h *DiskHealth) string {
+	if h.Verdict == "" {
+		return "DESCONOCIDO"
+	}
+	return h.Verdict
+}
+
+// valueOr devuelve s o, si está vacío, def
+func valueOr(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// displayGPU describe la GPU a la que está conectado un monitor: su modelo
+// si figura en la lista de GPUs, o la tarjeta DRM y su dirección
+func displayGPU(gpus []GPUInfo, m DisplayInfo) string {
+	for _, gpu := range gpus {
+		if gpu.PCIAddress == m.GPUAddress {
+			return fmt.Sprintf("%s %s (%s)", gpu.Vendor, gpu.Model, m.Card)
+		}
+	}
+	if m.GPUAddress != "" {
+		return fmt.Sprintf("%s (%s)", m.Card, m.GPUAddress)
+	}
+	return m.Card
+}
+
+// pcieLink describe un enlace PCIe por su generación y carriles ("PCIe 4.0
+// x16"), o por la velocidad si la generación no se reconoce
+func pcieLink(speed string, width int) string {
+	if gen := pcieGeneration(speed); gen != "" {
+		return fmt.Sprintf("PCIe %s x%d", gen, width)
+	}
+	if speed == "" {
+		return fmt.Sprintf("x%d", width)
+	}
+	return fmt.Sprintf("%s x%d", speed, width)
+}
@@ -0,0 +1,328 @@
+package hardware
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// fsProbeSize cubre todos los superbloques buscados al inicio del volumen
+// (el de btrfs está en 64 KiB)
+const fsProbeSize = 0x10000 + 4096
+
+// Firmas de superbloque
+const (
+	extMagic      = 0xEF53
+	mdraidMagic   = 0xa92b4efc
+	extSuperOff   = 1024
+	btrfsSuperOff = 0x10000
+)
+
+// probeFilesystem identifica el sistema de archivos o contenedor de un
+// volumen de size bytes por la firma de su superbloque, al estilo de blkid
+func probeFilesystem(r io.ReaderAt, size int64) FilesystemInfo {
+	buf := make([]byte, fsProbeSize)
+	n, _ := r.ReadAt(buf, 0)
+	buf = buf[:n]
+	if n < 512 {
+		return FilesystemInfo{}
+	}
+
+	// Contenedores primero: LUKS y LVM pueden dejar restos de un sistema de
+	// archivos anterior en los bloques que no sobrescriben
+	switch {
+	case bytes.HasPrefix(buf, []byte("LUKS\xba\xbe")) && n >= 208:
+		fs := FilesystemInfo{Type: "crypto_LUKS", UUID: trimNul(buf[168:208])}
+		if binary.BigEndian.Uint16(buf[6:8]) == 2 {
+			fs.Label = trimNul(buf[24:72])
+		}
+		return fs
+	}
+	if fs, ok := probeLVM(buf); ok {
+		return fs
+	}
+	if fs, ok := probeMDRaid(r, buf, size); ok {
+		return fs
+	}
+
+	// Sistemas de archivos con sector de arranque
+	if fs, ok := probeBootSector(r, buf); ok {
+		return fs
+	}
+
+	switch {
+	case n >= extSuperOff+136 && binary.LittleEndian.Uint16(buf[extSuperOff+56:]) == extMagic:
+		return probeExt(buf[extSuperOff:])
+	case bytes.HasPrefix(buf, []byte("XFSB")) && n >= 120:
+		return FilesystemInfo{Type: "xfs", UUID: formatUUID(buf[32:48]), Label: trimNul(buf[108:120])}
+	case n >= btrfsSuperOff+555 && string(buf[btrfsSuperOff+64:btrfsSuperOff+72]) == "_BHRfS_M":
+		sb := buf[btrfsSuperOff:]
+		return FilesystemInfo{Type: "btrfs", UUID: formatUUID(sb[32:48]), Label: trimNul(sb[299:555])}
+	}
+
+	// swap: la firma está al final de la primera página, cuyo tamaño depende
+	// de la arquitectura
+	for _, page := range []int{4096, 8192, 16384, 65536} {
+		if page > n {
+			break
+		}
+		switch string(buf[page-10 : page]) {
+		case "SWAPSPACE2":
+			return FilesystemInfo{Type: "swap", UUID: formatUUID(buf[1036:1052]), Label: trimNul(buf[1052:1068])}
+		case "SWAP-SPACE":
+			return FilesystemInfo{Type: "swap"}
+		}
+	}
+
+	return FilesystemInfo{}
+}
+
+// probeExt distingue ext2/3/4 por sus características: extents, 64bit o
+// flex_bg implican ext4; el journal sin ellas, ext3
+func probeExt(sb []byte) FilesystemInfo {
+	compat := binary.LittleEndian.Uint32(sb[92:96])
+	incompat := binary.LittleEndian.Uint32(sb[96:100])
+
+	fs := FilesystemInfo{Type: "ext2", UUID: formatUUID(sb[104:120]), Label: trimNul(sb[120:136])}
+	switch {
+	case incompat&0x0008 != 0:
+		fs.Type = "jbd" // journal externo
+	case incompat&(0x0040|0x0080|0x0200) != 0:
+		fs.Type = "ext4"
+	case compat&0x0004 != 0:
+		fs.Type = "ext3"
+	}
+	return fs
+}
+
+// probeLVM busca la etiqueta LABELONE de un volumen físico LVM2 en los
+// cuatro primeros sectores
+func probeLVM(buf []byte) (FilesystemInfo, bool) {
+	for s := 0; s < 4 && (s+1)*512 <= len(buf); s++ {
+		label := buf[s*512 : (s+1)*512]
+		if string(label[0:8]) != "LABELONE" || string(label[24:32]) != "LVM2 001" {
+			continue
+		}
+		off := int(binary.LittleEndian.Uint32(label[20:24]))
+		if off+32 > len(label) {
+			continue
+		}
+		id := string(label[off : off+32])
+		// Formato de LVM: 6-4-4-4-4-4-6
+		uuid := id[0:6] + "-" + id[6:10] + "-" + id[10:14] + "-" + id[14:18] + "-" +
+			id[18:22] + "-" + id[22:26] + "-" + id[26:32]
+		return FilesystemInfo{Type: "LVM2_member", UUID: uuid}, true
+	}
+	return FilesystemInfo{}, false
+}
+
+// probeMDRaid busca un superbloque de md: 1.1 al inicio, 1.2 a 4 KiB, 1.0 a
+// 8 KiB del final y 0.90 en los últimos 64 KiB alineados
+func probeMDRaid(r io.ReaderAt, buf []byte, size int64) (FilesystemInfo, bool) {
+	v1 := func(sb []byte) (FilesystemInfo, bool) {
+		if len(sb) < 64 || binary.LittleEndian.Uint32(sb[0:4]) != mdraidMagic {
+			return FilesystemInfo{}, false
+		}
+		if binary.LittleEndian.Uint32(sb[4:8]) != 1 {
+			return FilesystemInfo{}, false
+		}
+		return FilesystemInfo{Type: "linux_raid_member", UUID: formatUUID(sb[16:32]), Label: trimNul(sb[32:64])}, true
+	}
+
+	if fs, ok := v1(buf); ok {
+		return fs, true
+	}
+	if len(buf) >= 4096+64 {
+		if fs, ok := v1(buf[4096:]); ok {
+			return fs, true
+		}
+	}
+	if size < 128*1024 {
+		return FilesystemInfo{}, false
+	}
+
+	sb := make([]byte, 64)
+	if _, err := r.ReadAt(sb, ((size/512-16)&^7)*512); err == nil {
+		if fs, ok := v1(sb); ok {
+			return fs, true
+		}
+	}
+	if _, err := r.ReadAt(sb, (size&^0xFFFF)-0x10000); err == nil &&
+		binary.LittleEndian.Uint32(sb[0:4]) == mdraidMagic && binary.LittleEndian.Uint32(sb[4:8]) == 0 {
+		// 0.90: el UUID se reparte entre las palabras 5 y 13-15
+		id := make([]byte, 0, 16)
+		id = append(id, sb[20:24]...)
+		id = append(id, sb[52:64]...)
+		return FilesystemInfo{Type: "linux_raid_member", UUID: formatUUID(id)}, true
+	}
+	return FilesystemInfo{}, false
+}
+
+// probeBootSector reconoce los sistemas de archivos con sector de arranque
+// de tipo DOS: NTFS, BitLocker, exFAT y FAT12/16/32
+func probeBootSector(r io.ReaderAt, buf []byte) (FilesystemInfo, bool) {
+	if len(buf) < 512 || buf[510] != 0x55 || buf[511] != 0xAA {
+		return FilesystemInfo{}, false
+	}
+
+	switch {
+	case string(buf[3:11]) == "NTFS    ":
+		fs := FilesystemInfo{Type: "ntfs", UUID: fmt.Sprintf("%016X", binary.LittleEndian.Uint64(buf[0x48:0x50]))}
+		fs.Label = ntfsVolumeName(r, buf)
+		return fs, true
+	case string(buf[3:11]) == "-FVE-FS-":
+		return FilesystemInfo{Type: "bitlocker"}, true
+	case string(buf[3:11]) == "EXFAT   ":
+		fs := FilesystemInfo{Type: "exfat", UUID: volumeSerial(buf[100:104])}
+		fs.Label = exfatVolumeLabel(r, buf)
+		return fs, true
+	}
+
+	switch bps := binary.LittleEndian.Uint16(buf[11:13]); bps {
+	case 512, 1024, 2048, 4096:
+	default:
+		return FilesystemInfo{}, false
+	}
+	switch {
+	case string(buf[82:87]) == "FAT32":
+		return FilesystemInfo{Type: "vfat", UUID: volumeSerial(buf[67:71]), Label: fatLabel(buf[71:82])}, true
+	case string(buf[54:59]) == "FAT12", string(buf[54:59]) == "FAT16", string(buf[54:62]) == "FAT     ":
+		return FilesystemInfo{Type: "vfat", UUID: volumeSerial(buf[39:43]), Label: fatLabel(buf[43:54])}, true
+	}
+	return FilesystemInfo{}, false
+}
+
+// isBootSectorFilesystem indica si el sector 0 es el sector de arranque de
+// un sistema de archivos (y no un MBR): ambos terminan en 55AA
+func isBootSectorFilesystem(sector []byte) bool {
+	fs, _ := probeBootSector(nil, sector)
+	return fs.Type != ""
+}
+
+// ntfsVolumeName lee la etiqueta del atributo $VOLUME_NAME (60h) del
+// registro 3 ($Volume) de la MFT
+func ntfsVolumeName(r io.ReaderAt, boot []byte) string {
+	if r == nil {
+		return ""
+	}
+	bps := int64(binary.LittleEndian.Uint16(boot[0x0B:0x0D]))
+	spc := int64(boot[0x0D])
+	if spc > 0x80 {
+		spc = 1 << (256 - spc)
+	}
+	cluster := bps * spc
+	if bps == 0 || cluster == 0 {
+		return ""
+	}
+
+	recSize := int64(int8(boot[0x40]))
+	if recSize > 0 {
+		recSize *= cluster
+	} else {
+		recSize = 1 << -recSize
+	}
+	if recSize < 256 || recSize > 65536 {
+		return ""
+	}
+
+	mft := int64(binary.LittleEndian.Uint64(boot[0x30:0x38])) * cluster
+	rec := make([]byte, recSize)
+	if _, err := r.ReadAt(rec, mft+3*recSize); err != nil || string(rec[0:4]) != "FILE" {
+		return ""
+	}
+
+	// Deshacer el "update sequence array": los dos últimos bytes de cada
+	// sector se sustituyeron por un número de secuencia al escribir
+	usaOff := int(binary.LittleEndian.Uint16(rec[4:6]))
+	usaCount := int(binary.LittleEndian.Uint16(rec[6:8]))
+	for i := 1; i < usaCount; i++ {
+		pos := i*int(bps) - 2
+		if usaOff+2*i+2 > len(rec) || pos+2 > len(rec) {
+			break
+		}
+		copy(rec[pos:pos+2], rec[usaOff+2*i:usaOff+2*i+2])
+	}
+
+	// Las longitudes son de 32 bits: se comparan sin convertir a int, que en
+	// armv7 desbordaría con un registro corrupto
+	for a := int(binary.LittleEndian.Uint16(rec[0x14:0x16])); a+24 <= len(rec); {
+		typ := binary.LittleEndian.Uint32(rec[a : a+4])
+		length := binary.LittleEndian.Uint32(rec[a+4 : a+8])
+		if typ == 0xFFFFFFFF || length < 24 || uint64(length) > uint64(len(rec)-a) {
+			break
+		}
+		if typ == 0x60 && rec[a+8] == 0 {
+			vlen := binary.LittleEndian.Uint32(rec[a+0x10 : a+0x14])
+			voff := binary.LittleEndian.Uint16(rec[a+0x14 : a+0x16])
+			if uint64(voff)+uint64(vlen) <= uint64(length) {
+				start := a + int(voff)
+				return utf16String(rec[start : start+int(vlen)])
+			}
+			return ""
+		}
+		a += int(length)
+	}
+	return ""
+}
+
+// exfatVolumeLabel busca la entrada de etiqueta (83h) en el primer cluster
+// del directorio raíz
+func exfatVolumeLabel(r io.ReaderAt, boot []byte) string {
+	if r == nil || boot[108] < 9 || boot[108] > 12 || boot[109] > 25-boot[108] {
+		return ""
+	}
+	bps := int64(1) << boot[108]
+	cluster := bps << boot[109]
+	heap := int64(binary.LittleEndian.Uint32(boot[88:92])) * bps
+	root := int64(binary.LittleEndian.Uint32(boot[96:100]))
+	if root < 2 {
+		return ""
+	}
+
+	size := cluster
+	if size > 64*1024 {
+		size = 64 * 1024
+	}
+	dir := make([]byte, size)
+	if _, err := r.ReadAt(dir, heap+(root-2)*cluster); err != nil {
+		return ""
+	}
+	for i := 0; i+32 <= len(dir); i += 32 {
+		e := dir[i : i+32]
+		switch e[0] {
+		case 0x00:
+			return ""
+		case 0x83:
+			n := int(e[1])
+			if n > 11 {
+				n = 11
+			}
+			return utf16String(e[2 : 2+2*n])
+		}
+	}
+	return ""
+}
+
+// fatLabel limpia la etiqueta de 11 caracteres de un sector de arranque FAT
+func fatLabel(b []byte) string {
+	label := trimNul(b)
+	if label == "NO NAME" {
+		return ""
+	}
+	return label
+}
+
+// volumeSerial formatea un número de serie de volumen FAT/exFAT como XXXX-XXXX
+func volumeSerial(b []byte) string {
+	v := binary.LittleEndian.Uint32(b)
+	return fmt.Sprintf("%04X-%04X", v>>16, v&0xFFFF)
+}
+
+// formatUUID formatea 16 bytes como UUID en orden de red (minúsculas)
+func formatUUID(b []byte) string {
+	if allZero(b) {
+		return ""
+	}
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,142 @@
+package hardware
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Estrategias de GPUInfo.VRAMSource, en el orden en que se prueban
+const (
+	VRAMSourceNVIDIAProc = "nvidia-proc" // /proc/driver/nvidia/gpus/<addr>/information
+	VRAMSourceNVIDIASMI  = "nvidia-smi"  // nvidia-smi --query-gpu=memory.total
+	VRAMSourceDRM        = "drm"         // mem_info_vram_total del driver DRM (amdgpu, nouveau...)
+	VRAMSourceBAR        = "pci-bar"     // BAR prefetchable mayor: puede ser solo la apertura
+)
+
+// dpmLevel reconoce una línea de pp_dpm_sclk de amdgpu ("1: 1900Mhz *")
+var dpmLevel = regexp.MustCompile(`^\d+:\s*(\d+)Mhz(\s*\*)?`)
+
+// readGPUDetails completa gpu con lo que sysfs publica de la tarjeta: enlace
+// PCIe, estado de energía, GPU de arranque, nodos DRM, versión de la VBIOS y,
+// según el driver, carga y frecuencias del núcleo gráfico
+func (d *Detector) readGPUDetails(gpu *GPUInfo) {
+	base := "/sys/bus/pci/devices/" + gpu.PCIAddress
+
+	gpu.LinkSpeed = pcieSpeed(d.readFirst(base + "/current_link_speed"))
+	gpu.LinkWidth = d.readInt(base + "/current_link_width")
+	gpu.MaxLinkSpeed = pcieSpeed(d.readFirst(base + "/max_link_speed"))
+	gpu.MaxLinkWidth = d.readInt(base + "/max_link_width")
+	gpu.PowerState = d.readFirst(base + "/power_state")
+	gpu.BootVGA = d.readInt(base+"/boot_vga") == 1
+
+	for _, node := range d.listDir(base + "/drm") {
+		switch {
+		case strings.HasPrefix(node, "card"):
+			gpu.DRMCard = node
+		case strings.HasPrefix(node, "renderD"):
+			gpu.DRMRender = node
+		}
+	}
+
+	gpu.VBIOSVersion = d.readFirst(base + "/vbios_version")
+	if gpu.VBIOSVersion == "" {
+		gpu.VBIOSVersion = d.nvidiaVBIOS(gpu.PCIAddress)
+	}
+
+	gpu.BusyPercent = -1
+	switch gpu.Driver {
+	case "amdgpu", "radeon":
+		if s, err := d.readString(base + "/gpu_busy_percent"); err == nil {
+			if n, err := strconv.Atoi(s); err == nil {
+				gpu.BusyPercent = n
+			}
+		}
+		gpu.CurrentMHz, gpu.MinMHz, gpu.MaxMHz = d.amdgpuClocks(base + "/pp_dpm_sclk")
+	case "i915":
+		// act es la frecuencia real; RP0 y RPn, los límites del hardware
+		card := "/sys/class/drm/" + gpu.DRMCard
+		gpu.CurrentMHz = d.readInt(card + "/gt_act_freq_mhz")
+		gpu.MinMHz = d.readInt(card + "/gt_RPn_freq_mhz")
+		gpu.MaxMHz = d.readInt(card + "/gt_RP0_freq_mhz")
+	case "xe":
+		freq := base + "/tile0/gt0/freq0"
+		gpu.CurrentMHz = d.readInt(freq + "/act_freq")
+		gpu.MinMHz = d.readInt(freq + "/rpn_freq")
+		gpu.MaxMHz = d.readInt(freq + "/rp0_freq")
+	}
+}
+
+// amdgpuClocks interpreta los niveles DPM del reloj del núcleo: el mínimo,
+// el máximo y el activo (marcado con "*")
+func (d *Detector) amdgpuClocks(p string) (cur, lo, hi int) {
+	f, err := os.Open(d.path(p))
+	if err != nil {
+		return 0, 0, 0
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		m := dpmLevel.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if m == nil {
+			continue
+		}
+		mhz, _ := strconv.Atoi(m[1])
+		if lo == 0 || mhz < lo {
+			lo = mhz
+		}
+		hi = max(hi, mhz)
+		if m[2] != "" {
+			cur = mhz
+		}
+	}
+	return cur, lo, hi
+}
+
+// nvidiaVBIOS lee la versión de la VBIOS que publica el driver propietario
+// de NVIDIA ("Video BIOS: 94.04.3a.00.2c")
+func (d *Detector) nvidiaVBIOS(pciAddress string) string {
+	info, err := d.readString("/proc/driver/nvidia/gpus/" + pciAddress + "/information")
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(info, "\n") {
+		if v, ok := strings.CutPrefix(line, "Video BIOS:"); ok {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+// pcieSpeed normaliza la velocidad de enlace de sysfs ("8.0 GT/s PCIe" ->
+// "8.0 GT/s"); vacía si el kernel no la conoce
+func pcieSpeed(s string) string {
+	s = strings.TrimSpace(strings.TrimSuffix(s, "PCIe"))
+	if strings.HasPrefix(s, "Unknown") {
+		return ""
+	}
+	return s
+}
+
+// pcieGeneration devuelve la generación PCIe de una velocidad de enlace
+// ("16.0 GT/s" -> "4.0"), vacía si no se reconoce
+func pcieGeneration(speed string) string {
+	switch strings.TrimSuffix(speed, " GT/s") {
+	case "2.5":
+		return "1.0"
+	case "5.0":
+		return "2.0"
+	case "8.0":
+		return "3.0"
+	case "16.0":
+		return "4.0"
+	case "32.0":
+		return "5.0"
+	case "64.0":
+		return "6.0"
+	}
+	return ""
+}
@@ -4,8 +4,9 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
-	"net"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -20,6 +21,12 @@ import (
 // El ID resultante tiene el formato: HWSCAN-<UPPERCASE_HEX>
 // Ejemplo: HWSCAN-4C4C4544003237108037B7C04F343132
 //
+// En máquinas virtuales el UUID lo asigna el hipervisor (y se repite en VMs
+// clonadas), por lo que el ID lleva el prefijo HWSCAN-VM- para no mezclarlo
+// con equipos físicos. En contenedores el hardware y su UUID son los del
+// anfitrión: el ID (HWSCAN-CT-) combina el del anfitrión con el machine-id o
+// el nombre de host del contenedor.
+//
 // Este ID debe permanecer estable incluso después de:
 // - Reinstalación del sistema operativo
 // - Cambios de disco duro
@@ -30,8 +37,29 @@ import (
 // - CPU (en sistemas sin UUID de hardware)
 // - Toda la RAM (en sistemas sin UUID de hardware)
 func GenerateMachineID(info *HardwareInfo) string {
+	return NewDetector("/").GenerateMachineID(info)
+}
+
+// GenerateMachineID genera el identificador de la máquina leyendo DMI y las
+// interfaces de red bajo la raíz del detector. Ver GenerateMachineID.
+func (d *Detector) GenerateMachineID(info *HardwareInfo) string {
+	id := d.hardwareMachineID(info)
+
+	switch {
+	case info.Platform.Container != "":
+		hash := sha256.Sum256([]byte(id + "|" + d.containerIdentity()))
+		return fmt.Sprintf("HWSCAN-CT-%s", strings.ToUpper(hex.EncodeToString(hash[:16])))
+	case info.Platform.Hypervisor != "":
+		return strings.Replace(id, "HWSCAN-", "HWSCAN-VM-", 1)
+	}
+	return id
+}
+
+// hardwareMachineID genera el identificador a partir del hardware, sin
+// distinguir si es virtual
+func (d *Detector) hardwareMachineID(info *HardwareInfo) string {
 	// Estrategia 1: Intentar usar DMI Product UUID
-	if uuid := readDMIProductUUID(); isValidUUID(uuid) {
+	if uuid := d.readDMIProductUUID(); isValidUUID(uuid) {
 		// Limpiar y formatear el UUID
 		cleanUUID := strings.ReplaceAll(uuid, "-", "")
 		cleanUUID = strings.ReplaceAll(cleanUUID, " ", "")
@@ -45,7 +73,7 @@ func GenerateMachineID(info *HardwareInfo) string {
 	}
 
 	// Estrategia 3: Hash de MAC address + hardware básico
-	if id := generateFromMAC(info); id != "" {
+	if id := generateFromMAC(d.primaryMACAddress(), info); id != "" {
 		return id
 	}
 
@@ -54,14 +82,21 @@ func GenerateMachineID(info *HardwareInfo) string {
 	return generateFallbackID(info)
 }
 
+// containerIdentity devuelve lo que distingue a un contenedor de otros en el
+// mismo anfitrión: /etc/machine-id o, si no existe, el nombre de host
+func (d *Detector) containerIdentity() string {
+	if id, err := d.readString("/etc/machine-id"); err == nil && id != "" {
+		return id
+	}
+	host, _ := d.readString("/proc/sys/kernel/hostname")
+	return host
+}
+
 // readDMIProductUUID lee el UUID del producto desde DMI/SMBIOS
 // Este UUID es único por máquina y lo asigna el fabricante
-func readDMIProductUUID() string {
-	data, err := os.ReadFile("/sys/class/dmi/id/product_uuid")
-	if err != nil {
-		return ""
-	}
-	return strings.TrimSpace(string(data))
+func (d *Detector) readDMIProductUUID() string {
+	uuid, _ := d.readString("/sys/class/dmi/id/product_uuid")
+	return uuid
 }
 
 // isValidUUID verifica que un UUID sea válido y no sea un valor placeholder
@@ -145,9 +180,7 @@ func generateFromDMI(info *HardwareInfo) string {
 
 // generateFromMAC genera un ID basado en la dirección MAC principal
 // Esto es menos ideal pero funciona cuando no hay información DMI
-func generateFromMAC(info *HardwareInfo) string {
-	// Obtener la primera MAC address válida (no loopback, no virtual)
-	mac := getPrimaryMACAddress()
+func generateFromMAC(mac string, info *HardwareInfo) string {
 	if mac == "" {
 		return ""
 	}
@@ -172,28 +205,48 @@ func generateFromMAC(info *HardwareInfo) string {
 	return fmt.Sprintf("HWSCAN-%s", hexHash)
 }
 
-// getPrimaryMACAddress obtiene la dirección MAC de la interfaz de red principal
-// Ignora interfaces loopback, virtuales y desconectadas
-func getPrimaryMACAddress() string {
-	interfaces, err := net.Interfaces()
+// primaryMACAddress obtiene la dirección MAC de la interfaz de red principal
+// leyendo /sys/class/net bajo la raíz del detector, en orden de ifindex
+// (el mismo orden que devuelve net.Interfaces).
+// Ignora interfaces loopback, virtuales y sin dirección MAC
+func (d *Detector) primaryMACAddress() string {
+	entries, err := os.ReadDir(d.path("/sys/class/net"))
 	if err != nil {
 		return ""
 	}
 
-	// Buscar la primera interfaz física válida
-	for _, iface := range interfaces {
-		// Ignorar loopback
-		if iface.Flags&net.FlagLoopback != 0 {
+	type netIface struct {
+		name  string
+		index int
+		mac   string
+	}
+	var interfaces []netIface
+	for _, entry := range entries {
+		base := "/sys/class/net/" + entry.Name()
+
+		// Ignorar loopback (ARPHRD_LOOPBACK = 772)
+		if t, _ := d.readString(base + "/type"); t == "772" {
 			continue
 		}
 
 		// Ignorar interfaces sin dirección MAC
-		if len(iface.HardwareAddr) == 0 {
+		mac, _ := d.readString(base + "/address")
+		if mac == "" || mac == "00:00:00:00:00:00" {
 			continue
 		}
 
+		idx, _ := d.readString(base + "/ifindex")
+		index, _ := strconv.Atoi(idx)
+		interfaces = append(interfaces, netIface{name: entry.Name(), index: index, mac: mac})
+	}
+	sort.Slice(interfaces, func(i, j int) bool {
+		return interfaces[i].index < interfaces[j].index
+	})
+
+	// Buscar la primera interfaz física válida
+	for _, iface := range interfaces {
 		// Ignorar interfaces que parecen virtuales
-		name := strings.ToLower(iface.Name)
+		name := strings.ToLower(iface.name)
 		if strings.Contains(name, "docker") ||
 			strings.Contains(name, "veth") ||
 			strings.Contains(name, "br-") ||
@@ -202,7 +255,7 @@ func getPrimaryMACAddress() string {
 		}
 
 		// Retornar la primera MAC válida
-		return iface.HardwareAddr.String()
+		return iface.mac
 	}
 
 	return ""
@@ -0,0 +1,142 @@
+package hardware
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Tipos de tarjeta en /sys/block/mmcblkN/device/type
+const (
+	mmcTypeMMC = "MMC" // eMMC soldada o tarjeta MMC
+	mmcTypeSD  = "SD"
+)
+
+// emmcManufacturers son los fabricantes JEDEC más habituales en el campo
+// MID del CID de una eMMC
+var emmcManufacturers = map[uint8]string{
+	0x11: "Toshiba",
+	0x13: "Micron",
+	0x15: "Samsung",
+	0x45: "SanDisk",
+	0x70: "Kingston",
+	0x88: "Foresee",
+	0x90: "SK Hynix",
+	0xfe: "Micron",
+}
+
+// sdManufacturers son los fabricantes más habituales en el campo MID del
+// CID de una tarjeta SD (asignados por la SD Association)
+var sdManufacturers = map[uint8]string{
+	0x01: "Panasonic",
+	0x02: "Toshiba",
+	0x03: "SanDisk",
+	0x1b: "Samsung",
+	0x1d: "ADATA",
+	0x27: "Phison",
+	0x28: "Lexar",
+	0x31: "Silicon Power",
+	0x41: "Kingston",
+	0x74: "Transcend",
+	0x76: "Patriot",
+	0x82: "Sony",
+}
+
+// mmcCID son los campos del registro CID (128 bits) de una eMMC o SD
+type mmcCID struct {
+	manufacturerID uint8
+	product        string
+	revision       string
+	serial         uint32
+	date           string // MM/AAAA
+}
+
+// parseMMCCID decodifica el CID en hexadecimal de /sys/block/mmcblkN/device/cid.
+// El formato difiere entre eMMC (JESD84) y SD (SD Physical Layer).
+func parseMMCCID(s string, sd bool) (mmcCID, error) {
+	b, err := hex.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return mmcCID{}, err
+	}
+	if len(b) != 16 {
+		return mmcCID{}, fmt.Errorf("CID de %d bytes (se esperaban 16)", len(b))
+	}
+
+	be32 := func(p []byte) uint32 {
+		return uint32(p[0])<<24 | uint32(p[1])<<16 | uint32(p[2])<<8 | uint32(p[3])
+	}
+
+	cid := mmcCID{manufacturerID: b[0]}
+	if sd {
+		// MID[127:120] OID[119:104] PNM[103:64] PRV[63:56] PSN[55:24] MDT[19:8]
+		cid.product = trimNul(b[3:8])
+		cid.revision = fmt.Sprintf("%d.%d", b[8]>>4, b[8]&0x0F)
+		cid.serial = be32(b[9:13])
+		year := 2000 + (int(b[13]&0x0F)<<4 | int(b[14]>>4))
+		cid.date = fmt.Sprintf("%02d/%d", b[14]&0x0F, year)
+	} else {
+		// MID[127:120] CBX[113:112] OID[111:104] PNM[103:56] PRV[55:48]
+		// PSN[47:16] MDT[15:8]. El año es relativo a 1997; las eMMC 4.41+
+		// lo reinterpretan desde 2013 (el kernel lo corrige con EXT_CSD).
+		cid.product = trimNul(b[3:9])
+		cid.revision = fmt.Sprintf("%d.%d", b[9]>>4, b[9]&0x0F)
+		cid.serial = be32(b[10:14])
+		cid.date = fmt.Sprintf("%02d/%d", b[14]>>4, 1997+int(b[14]&0x0F))
+	}
+	return cid, nil
+}
+
+// mmcManufacturer devuelve el nombre del fabricante de un MID o, si no se
+// conoce, el propio identificador
+func mmcManufacturer(mid uint8, sd bool) string {
+	names := emmcManufacturers
+	if sd {
+		names = sdManufacturers
+	}
+	if name, ok := names[mid]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%02x", mid)
+}
+
+// readMMCIdentity completa un disco eMMC/SD desde su CID: fabricante,
+// producto, serie, revisión y fecha de fabricación. La fecha que calcula el
+// kernel (device/date) tiene preferencia porque corrige el año de las eMMC
+// modernas.
+func (d *Detector) readMMCIdentity(disk *DiskInfo) {
+	base := "/sys/block/" + disk.Name + "/device"
+
+	cardType, _ := d.readString(base + "/type")
+	sd := cardType == mmcTypeSD
+	switch cardType {
+	case mmcTypeMMC:
+		disk.Type = "eMMC"
+	case mmcTypeSD:
+		disk.Type = "SD"
+	default:
+		disk.Type = "MMC"
+	}
+
+	cid, err := parseMMCCID(d.readFirst(base+"/cid"), sd)
+	if err != nil {
+		// Sin CID: valores ya decodificados por el kernel
+		disk.Serial, _ = d.readString(base + "/serial")
+		disk.Firmware = d.readFirst(base+"/fwrev", base+"/prv")
+		disk.ManufactureDate, _ = d.readString(base + "/date")
+		return
+	}
+
+	disk.Vendor = mmcManufacturer(cid.manufacturerID, sd)
+	if disk.Model == "" {
+		disk.Model = cid.product
+	}
+	disk.Serial = fmt.Sprintf("0x%08x", cid.serial)
+	disk.Firmware = d.readFirst(base+"/fwrev", base+"/prv")
+	if disk.Firmware == "" {
+		disk.Firmware = cid.revision
+	}
+	disk.ManufactureDate = d.readFirst(base + "/date")
+	if disk.ManufactureDate == "" {
+		disk.ManufactureDate = cid.date
+	}
+}
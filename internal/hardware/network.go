@@ -0,0 +1,158 @@
+package hardware
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// arphrdLoopback es el tipo de enlace de las interfaces loopback
+const arphrdLoopback = "772"
+
+// detectNetwork lista las interfaces de red desde /sys/class/net, en orden
+// de ifindex. Las interfaces sin dispositivo físico (bridges, veth, tun) se
+// incluyen marcadas como virtuales; loopback se omite.
+func (d *Detector) detectNetwork(rep *report) ([]NetworkInterface, error) {
+	interfaces := make([]NetworkInterface, 0)
+
+	entries, err := os.ReadDir(d.path("/sys/class/net"))
+	if err != nil {
+		return interfaces, err
+	}
+
+	if !d.live() {
+		rep.note("raíz distinta de /: firmware y MAC permanente no disponibles (requieren ethtool)")
+	}
+
+	indexes := make(map[string]int)
+	for _, entry := range entries {
+		name := entry.Name()
+		base := "/sys/class/net/" + name
+
+		if t, _ := d.readString(base + "/type"); t == arphrdLoopback {
+			continue
+		}
+
+		iface := NetworkInterface{Name: name}
+		iface.MAC, _ = d.readString(base + "/address")
+		iface.OperState, _ = d.readString(base + "/operstate")
+		if mtu, err := d.readString(base + "/mtu"); err == nil {
+			iface.MTU, _ = strconv.Atoi(mtu)
+		}
+
+		// carrier, speed y duplex devuelven EINVAL con la interfaz apagada
+		if carrier, err := d.readString(base + "/carrier"); err == nil {
+			iface.Carrier = carrier == "1"
+		}
+		if iface.Carrier {
+			if speed, err := d.readString(base + "/speed"); err == nil {
+				// -1 (o 4294967295 en kernels antiguos) = desconocida
+				if v, err := strconv.ParseInt(speed, 10, 64); err == nil && v > 0 && v != 4294967295 {
+					iface.SpeedMbps = int(v)
+				}
+			}
+			if duplex, err := d.readString(base + "/duplex"); err == nil && duplex != "unknown" {
+				iface.Duplex = duplex
+			}
+		}
+
+		iface.Wireless = d.exists(base+"/wireless") || d.exists(base+"/phy80211")
+		iface.Virtual = !d.exists(base + "/device")
+		if !iface.Virtual {
+			iface.Driver = d.linkBase(base + "/device/driver")
+			d.netParent(base+"/device", &iface)
+		}
+
+		if d.live() && !iface.Virtual {
+			if info, err := ethtoolQuery(name); err == nil {
+				iface.FirmwareVersion = info.firmware
+				iface.PermanentMAC = info.permanentMAC
+				fillEmpty(&iface.Driver, info.driver)
+			} else {
+				rep.note("ethtool %s: %v", name, err)
+			}
+		}
+
+		idx, _ := d.readString(base + "/ifindex")
+		indexes[name], _ = strconv.Atoi(idx)
+		interfaces = append(interfaces, iface)
+	}
+
+	sort.SliceStable(interfaces, func(i, j int) bool {
+		return indexes[interfaces[i].Name] < indexes[interfaces[j].Name]
+	})
+
+	return interfaces, nil
+}
+
+// netParent busca el dispositivo PCI o USB del que cuelga una interfaz
+// subiendo por el árbol de /sys/devices desde el enlace device (ej: una
+// interfaz virtio cuelga de virtio3, que cuelga de 0000:00:03.0)
+func (d *Detector) netParent(devLink string, iface *NetworkInterface) {
+	root, err := filepath.EvalSymlinks(d.path("/sys/devices"))
+	if err != nil {
+		return
+	}
+	dir, err := filepath.EvalSymlinks(d.path(devLink))
+	if err != nil {
+		return
+	}
+
+	for strings.HasPrefix(dir, root+string(filepath.Separator)) {
+		subsystem, err := os.Readlink(filepath.Join(dir, "subsystem"))
+		if err == nil {
+			switch filepath.Base(subsystem) {
+			case "pci":
+				iface.Bus = "pci"
+				iface.ParentAddress = filepath.Base(dir)
+				iface.Model = d.pciModel(iface.ParentAddress)
+				return
+			case "usb":
+				// La interfaz de red cuelga de una interfaz USB (1-2:1.0);
+				// los nombres están en el dispositivo (1-2)
+				if strings.Contains(filepath.Base(dir), ":") {
+					dir = filepath.Dir(dir)
+				}
+				iface.Bus = "usb"
+				iface.ParentAddress = filepath.Base(dir)
+				iface.Model = usbSysfsName(dir)
+				return
+			}
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// pciModel devuelve el nombre del dispositivo PCI con la dirección dada, o ""
+// si no se enumeró
+func (d *Detector) pciModel(address string) string {
+	devices, _ := d.pciDevices()
+	for _, dev := range devices {
+		if dev.Address == address {
+			return pciDisplayName(dev)
+		}
+	}
+	return ""
+}
+
+// usbSysfsName devuelve el nombre de un dispositivo USB según sus
+// descriptores (manufacturer y product) en sysfs
+func usbSysfsName(dir string) string {
+	var parts []string
+	for _, f := range []string{"manufacturer", "product"} {
+		if data, err := os.ReadFile(filepath.Join(dir, f)); err == nil {
+			if s := strings.TrimSpace(string(data)); s != "" {
+				parts = append(parts, s)
+			}
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// exists indica si una ruta existe bajo la raíz del detector
+func (d *Detector) exists(p string) bool {
+	_, err := os.Stat(d.path(p))
+	return err == nil
+}
@@ -0,0 +1,150 @@
+package hardware
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Constantes de la interfaz CD-ROM del kernel (linux/cdrom.h)
+const (
+	cdromDriveStatus   = 0x5326
+	cdromGetCapability = 0x5331
+	cdslCurrent        = 0x7FFFFFFF
+
+	cdsNoDisc        = 1
+	cdsTrayOpen      = 2
+	cdsDriveNotReady = 3
+	cdsDiscOK        = 4
+)
+
+// Estados de OpticalInfo.Status
+const (
+	OpticalDisc     = "disc"
+	OpticalNoDisc   = "no disc"
+	OpticalTrayOpen = "tray open"
+	OpticalNotReady = "not ready"
+)
+
+// opticalCapabilities relaciona los bits de CDROM_GET_CAPABILITY con las
+// líneas de /proc/sys/dev/cdrom/info y el nombre que usa hwscan
+var opticalCapabilities = []struct {
+	bit   int
+	proc  string
+	label string
+}{
+	{0x100, "Can play audio", "audio"},
+	{0x20, "Can read multisession", "multisession"},
+	{0x2000, "Can write CD-R", "CD-R"},
+	{0x4000, "Can write CD-RW", "CD-RW"},
+	{0x8000, "Can read DVD", "DVD"},
+	{0x10000, "Can write DVD-R", "DVD-R"},
+	{0x20000, "Can write DVD-RAM", "DVD-RAM"},
+	{0x80000, "Can read MRW", "MRW"},
+	{0x100000, "Can write MRW", "MRW-W"},
+	{0x2, "Can open tray", "tray"},
+}
+
+// readOptical completa la información de una unidad óptica: capacidades y
+// velocidad desde /proc/sys/dev/cdrom/info y, sobre el sistema en ejecución,
+// el estado de la bandeja mediante los ioctl CD-ROM
+func (d *Detector) readOptical(rep *report, disk *DiskInfo) {
+	optical := &OpticalInfo{Capabilities: make([]string, 0)}
+
+	drives := parseCDROMInfo(d.path("/proc/sys/dev/cdrom/info"))
+	if fields, ok := drives[disk.Name]; ok {
+		optical.SpeedX, _ = strconv.Atoi(fields["drive speed"])
+		for _, c := range opticalCapabilities {
+			if fields[c.proc] == "1" {
+				optical.Capabilities = append(optical.Capabilities, c.label)
+			}
+		}
+	}
+
+	if d.live() {
+		caps, status, err := cdromQuery("/dev/" + disk.Name)
+		switch {
+		case err != nil:
+			rep.note("unidad óptica %s: %v", disk.Name, err)
+		default:
+			if len(optical.Capabilities) == 0 {
+				for _, c := range opticalCapabilities {
+					if caps&c.bit != 0 {
+						optical.Capabilities = append(optical.Capabilities, c.label)
+					}
+				}
+			}
+			optical.Status = status
+		}
+	}
+
+	disk.Optical = optical
+}
+
+// parseCDROMInfo interpreta /proc/sys/dev/cdrom/info: una línea por
+// característica con una columna por unidad, en el orden de "drive name"
+func parseCDROMInfo(path string) map[string]map[string]string {
+	drives := make(map[string]map[string]string)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return drives
+	}
+	defer f.Close()
+
+	var names []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		values := strings.Fields(value)
+		if key == "drive name" {
+			names = values
+			for _, name := range names {
+				drives[name] = make(map[string]string)
+			}
+			continue
+		}
+		for i, v := range values {
+			if i < len(names) {
+				drives[names[i]][key] = v
+			}
+		}
+	}
+	return drives
+}
+
+// cdromQuery consulta las capacidades (CDROM_GET_CAPABILITY) y el estado
+// de la bandeja (CDROM_DRIVE_STATUS) de una unidad óptica. Se abre con
+// O_NONBLOCK para no esperar a que haya disco.
+func cdromQuery(dev string) (int, string, error) {
+	f, err := os.OpenFile(dev, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+
+	caps, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), cdromGetCapability, 0)
+	if errno != 0 {
+		return 0, "", errno
+	}
+
+	status := ""
+	if s, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), cdromDriveStatus, cdslCurrent); errno == 0 {
+		switch s {
+		case cdsNoDisc:
+			status = OpticalNoDisc
+		case cdsTrayOpen:
+			status = OpticalTrayOpen
+		case cdsDriveNotReady:
+			status = OpticalNotReady
+		case cdsDiscOK:
+			status = OpticalDisc
+		}
+	}
+	return int(caps), status, nil
+}
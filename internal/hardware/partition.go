@@ -0,0 +1,452 @@
+package hardware
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Tipos de tabla de particiones en DiskInfo.PartitionTable
+const (
+	PartitionTableGPT = "gpt"
+	PartitionTableMBR = "mbr"
+)
+
+// maxGPTEntries limita las entradas GPT leídas (el estándar reserva 128)
+const maxGPTEntries = 256
+
+// gptTypes son los tipos de partición GPT más habituales
+var gptTypes = map[string]string{
+	"C12A7328-F81F-11D2-BA4B-00A0C93EC93B": "EFI System",
+	"21686148-6449-6E6F-744E-656564454649": "BIOS boot",
+	"E3C9E316-0B5C-4DB8-817D-F92DF00215AE": "Microsoft reserved",
+	"EBD0A0A2-B9E5-4433-87C0-68B6B72699C7": "Microsoft basic data",
+	"DE94BBA4-06D1-4D40-A16A-BFD50179D6AC": "Windows recovery environment",
+	"5808C8AA-7E8F-42E0-85D2-E1E90434CFB3": "Microsoft LDM metadata",
+	"AF9B60A0-1431-4F62-BC68-3311714A69AD": "Microsoft LDM data",
+	"0FC63DAF-8483-4772-8E79-3D69D8477DE4": "Linux filesystem",
+	"4F68BCE3-E8CD-4DB1-96E7-FBCAF984B709": "Linux root (x86-64)",
+	"B921B045-1DF0-41C3-AF44-4C6F280D3FAE": "Linux root (ARM-64)",
+	"933AC7E1-2EB4-4F13-B844-0E14E2AEF915": "Linux home",
+	"BC13C2FF-59E6-4262-A352-B275FD6F7172": "Linux extended boot",
+	"0657FD6D-A4AB-43C4-84E5-0933C84B4F4F": "Linux swap",
+	"E6D6D379-F507-44C2-A23C-238F2A3DF928": "Linux LVM",
+	"A19D880F-05FC-4D3B-A006-743F0F84911E": "Linux RAID",
+	"CA7D7CCB-63ED-4C53-861C-1742536059CC": "Linux LUKS",
+	"7C3457EF-0000-11AA-AA11-00306543ECAC": "Apple APFS",
+	"48465300-0000-11AA-AA11-00306543ECAC": "Apple HFS/HFS+",
+	"516E7CB4-6ECF-11D6-8FF8-00022D09712B": "FreeBSD data",
+}
+
+// mbrTypes son los tipos de partición MBR más habituales
+var mbrTypes = map[uint8]string{
+	0x01: "FAT12",
+	0x04: "FAT16 <32M",
+	0x05: "Extended",
+	0x06: "FAT16",
+	0x07: "HPFS/NTFS/exFAT",
+	0x0b: "W95 FAT32",
+	0x0c: "W95 FAT32 (LBA)",
+	0x0e: "W95 FAT16 (LBA)",
+	0x0f: "W95 Ext'd (LBA)",
+	0x17: "Hidden HPFS/NTFS",
+	0x1b: "Hidden W95 FAT32",
+	0x1c: "Hidden W95 FAT32 (LBA)",
+	0x27: "Hidden NTFS WinRE",
+	0x82: "Linux swap",
+	0x83: "Linux",
+	0x85: "Linux extended",
+	0x8e: "Linux LVM",
+	0xa5: "FreeBSD",
+	0xaf: "HFS / HFS+",
+	0xee: "GPT",
+	0xef: "EFI (FAT-12/16/32)",
+	0xfd: "Linux raid autodetect",
+}
+
+// errNoPartitionTable indica que el disco no tiene tabla MBR ni GPT
+var errNoPartitionTable = errors.New("sin tabla de particiones")
+
+// readPartitionTable lee la tabla GPT o MBR de un disco con el tamaño de
+// sector lógico dado. Devuelve el tipo de tabla, su identificador (GUID del
+// disco o firma MBR) y las particiones en orden de número.
+func readPartitionTable(r io.ReaderAt, sectorSize int) (string, string, []Partition, error) {
+	mbr := make([]byte, 512)
+	if _, err := r.ReadAt(mbr, 0); err != nil {
+		return "", "", nil, err
+	}
+	if mbr[510] != 0x55 || mbr[511] != 0xAA || isBootSectorFilesystem(mbr) {
+		return "", "", nil, errNoPartitionTable
+	}
+
+	// Un MBR protector (tipo EEh) indica GPT
+	for i := 0; i < 4; i++ {
+		if mbr[446+i*16+4] == 0xEE {
+			id, parts, err := readGPT(r, sectorSize)
+			if err == nil {
+				return PartitionTableGPT, id, parts, nil
+			}
+			break
+		}
+	}
+
+	// Entradas con indicador de arranque distinto de 00h/80h: no es un MBR
+	parts, err := readMBR(r, mbr, sectorSize)
+	if err != nil {
+		return "", "", nil, err
+	}
+	id := fmt.Sprintf("%08x", binary.LittleEndian.Uint32(mbr[440:444]))
+	return PartitionTableMBR, id, parts, nil
+}
+
+// readGPT lee la cabecera GPT primaria (LBA 1) y sus entradas
+func readGPT(r io.ReaderAt, sectorSize int) (string, []Partition, error) {
+	hdr := make([]byte, 92)
+	if _, err := r.ReadAt(hdr, int64(sectorSize)); err != nil {
+		return "", nil, err
+	}
+	if string(hdr[0:8]) != "EFI PART" {
+		return "", nil, errors.New("firma GPT no encontrada")
+	}
+
+	diskGUID := formatGUID(hdr[56:72])
+	entriesLBA := binary.LittleEndian.Uint64(hdr[72:80])
+	count := binary.LittleEndian.Uint32(hdr[80:84])
+	entrySize := binary.LittleEndian.Uint32(hdr[84:88])
+	if entrySize < 128 || entrySize > 4096 || count == 0 {
+		return "", nil, fmt.Errorf("cabecera GPT inválida (%d entradas de %d bytes)", count, entrySize)
+	}
+	if count > maxGPTEntries {
+		count = maxGPTEntries
+	}
+
+	table := make([]byte, int(count)*int(entrySize))
+	if _, err := r.ReadAt(table, int64(entriesLBA)*int64(sectorSize)); err != nil {
+		return "", nil, err
+	}
+
+	parts := make([]Partition, 0)
+	for i := 0; i < int(count); i++ {
+		e := table[i*int(entrySize) : (i+1)*int(entrySize)]
+		if allZero(e[0:16]) {
+			continue
+		}
+		first := binary.LittleEndian.Uint64(e[32:40])
+		last := binary.LittleEndian.Uint64(e[40:48])
+		if last < first {
+			continue
+		}
+
+		typeGUID := formatGUID(e[0:16])
+		parts = append(parts, Partition{
+			Number:     i + 1,
+			StartBytes: first * uint64(sectorSize),
+			SizeBytes:  (last - first + 1) * uint64(sectorSize),
+			Type:       typeGUID,
+			TypeName:   gptTypes[typeGUID],
+			PartUUID:   strings.ToLower(formatGUID(e[16:32])),
+			PartLabel:  utf16String(e[56:128]),
+		})
+	}
+	return diskGUID, parts, nil
+}
+
+// readMBR lee las cuatro entradas primarias del MBR y las particiones
+// lógicas de la cadena de EBR de la partición extendida (numeradas desde 5)
+func readMBR(r io.ReaderAt, mbr []byte, sectorSize int) ([]Partition, error) {
+	parts := make([]Partition, 0)
+	var extStart uint64
+
+	for i := 0; i < 4; i++ {
+		e := mbr[446+i*16 : 446+(i+1)*16]
+		typ := e[4]
+		start := uint64(binary.LittleEndian.Uint32(e[8:12]))
+		sectors := uint64(binary.LittleEndian.Uint32(e[12:16]))
+		if typ == 0 || sectors == 0 {
+			continue
+		}
+		if e[0] != 0x00 && e[0] != 0x80 {
+			return nil, errNoPartitionTable
+		}
+
+		parts = append(parts, mbrPartition(i+1, typ, start, sectors, sectorSize))
+		if isExtended(typ) && extStart == 0 {
+			extStart = start
+		}
+	}
+
+	if extStart != 0 {
+		parts = append(parts, readLogicalPartitions(r, extStart, sectorSize)...)
+	}
+	return parts, nil
+}
+
+// readLogicalPartitions recorre la lista enlazada de EBR. Las direcciones de
+// cada EBR son relativas al inicio de la partición extendida; las de las
+// particiones, al EBR que las contiene.
+func readLogicalPartitions(r io.ReaderAt, extStart uint64, sectorSize int) []Partition {
+	parts := make([]Partition, 0)
+	ebr := make([]byte, 512)
+	next := uint64(0)
+	seen := make(map[uint64]bool)
+
+	for n := 5; n < 5+128; n++ {
+		cur := extStart + next
+		if seen[cur] {
+			break // EBR en bucle
+		}
+		seen[cur] = true
+
+		if _, err := r.ReadAt(ebr, int64(cur)*int64(sectorSize)); err != nil {
+			break
+		}
+		if ebr[510] != 0x55 || ebr[511] != 0xAA {
+			break
+		}
+
+		e := ebr[446:462]
+		if typ := e[4]; typ != 0 {
+			start := cur + uint64(binary.LittleEndian.Uint32(e[8:12]))
+			sectors := uint64(binary.LittleEndian.Uint32(e[12:16]))
+			parts = append(parts, mbrPartition(n, typ, start, sectors, sectorSize))
+		}
+
+		link := ebr[462:478]
+		if !isExtended(link[4]) {
+			break
+		}
+		next = uint64(binary.LittleEndian.Uint32(link[8:12]))
+	}
+	return parts
+}
+
+// mbrPartition construye una Partition a partir de una entrada MBR/EBR
+func mbrPartition(n int, typ uint8, start, sectors uint64, sectorSize int) Partition {
+	return Partition{
+		Number:     n,
+		StartBytes: start * uint64(sectorSize),
+		SizeBytes:  sectors * uint64(sectorSize),
+		Type:       fmt.Sprintf("0x%02x", typ),
+		TypeName:   mbrTypes[typ],
+	}
+}
+
+// isExtended indica si un tipo MBR es una partición extendida
+func isExtended(typ uint8) bool {
+	return typ == 0x05 || typ == 0x0f || typ == 0x85
+}
+
+// formatGUID formatea un GUID en el orden de bytes mixto de GPT (los tres
+// primeros campos en little endian)
+func formatGUID(b []byte) string {
+	return fmt.Sprintf("%08X-%04X-%04X-%X-%X",
+		binary.LittleEndian.Uint32(b[0:4]),
+		binary.LittleEndian.Uint16(b[4:6]),
+		binary.LittleEndian.Uint16(b[6:8]),
+		b[8:10], b[10:16])
+}
+
+// utf16String decodifica una cadena UTF-16LE terminada en NUL
+func utf16String(b []byte) string {
+	u := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		c := binary.LittleEndian.Uint16(b[i:])
+		if c == 0 {
+			break
+		}
+		u = append(u, c)
+	}
+	return strings.TrimSpace(string(utf16.Decode(u)))
+}
+
+// readPartitions completa la tabla de particiones de un disco y el sistema
+// de archivos de cada partición leyendo el propio dispositivo. Sin acceso al
+// dispositivo (sin root) o vencido el plazo del detector se usan las
+// particiones que expone sysfs, sin etiquetas ni UUID.
+func (d *Detector) readPartitions(ctx context.Context, rep *report, disk *DiskInfo) {
+	sectorSize := disk.LogicalBlockSize
+	if sectorSize == 0 {
+		sectorSize = 512
+	}
+	disk.Partitions = make([]Partition, 0)
+
+	// Un disco con sectores ilegibles puede bloquear cada lectura durante
+	// los reintentos del kernel: no se abre ninguno más pasado el plazo
+	if ctx.Err() != nil {
+		rep.note("particiones de %s: se agotó el tiempo del detector, se usan las de sysfs", disk.Name)
+		disk.Partitions = d.sysfsPartitions(disk.Name)
+		return
+	}
+
+	f, err := os.Open(d.path("/dev/" + disk.Name))
+	if err != nil {
+		if os.IsPermission(err) {
+			rep.note("particiones de %s: se requieren privilegios de root para leer etiquetas y UUID", disk.Name)
+		} else if !os.IsNotExist(err) {
+			rep.note("particiones de %s: %v", disk.Name, err)
+		}
+		disk.Partitions = d.sysfsPartitions(disk.Name)
+		return
+	}
+	defer f.Close()
+	dev := ctxReaderAt{ctx: ctx, r: f}
+
+	table, id, parts, err := readPartitionTable(dev, sectorSize)
+	switch {
+	case errors.Is(err, errNoPartitionTable):
+		// Sistema de archivos o contenedor directamente sobre el disco
+		disk.Filesystem = probeFilesystem(dev, int64(disk.SizeBytes))
+	case err != nil && ctx.Err() != nil:
+		rep.note("particiones de %s: se agotó el tiempo del detector, se usan las de sysfs", disk.Name)
+		disk.Partitions = d.sysfsPartitions(disk.Name)
+		return
+	case err != nil:
+		rep.note("tabla de particiones de %s: %v", disk.Name, err)
+		disk.Partitions = d.sysfsPartitions(disk.Name)
+		return
+	}
+
+	for i := range parts {
+		p := &parts[i]
+		p.Name = partitionName(disk.Name, p.Number)
+		if isExtended(mbrTypeCode(p.Type)) && table == PartitionTableMBR {
+			continue
+		}
+		if ctx.Err() != nil {
+			continue
+		}
+		p.Filesystem = probeFilesystem(io.NewSectionReader(dev, int64(p.StartBytes), int64(p.SizeBytes)), int64(p.SizeBytes))
+	}
+	if ctx.Err() != nil {
+		rep.note("sistemas de archivos de %s: se agotó el tiempo del detector", disk.Name)
+	}
+
+	disk.PartitionTable = table
+	disk.PartitionTableID = id
+	disk.Partitions = parts
+}
+
+// ctxReaderAt corta las lecturas de un dispositivo en cuanto vence ctx, de
+// modo que las tablas de particiones y las sondas de sistemas de archivos no
+// sigan leyendo un disco lento pasado el plazo del detector
+type ctxReaderAt struct {
+	ctx context.Context
+	r   io.ReaderAt
+}
+
+func (c ctxReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.ReadAt(p, off)
+}
+
+// sysfsPartitions lista las particiones de /sys/block/<disk>/<disk>N, con
+// inicio y tamaño en sectores de 512 bytes
+func (d *Detector) sysfsPartitions(name string) []Partition {
+	parts := make([]Partition, 0)
+
+	entries, err := os.ReadDir(d.path("/sys/block/" + name))
+	if err != nil {
+		return parts
+	}
+	for _, entry := range entries {
+		base := "/sys/block/" + name + "/" + entry.Name()
+		num, err := d.readString(base + "/partition")
+		if err != nil {
+			continue
+		}
+		n, _ := strconv.Atoi(num)
+		start, _ := strconv.ParseUint(d.readFirst(base+"/start"), 10, 64)
+		size, _ := strconv.ParseUint(d.readFirst(base+"/size"), 10, 64)
+		parts = append(parts, Partition{
+			Number:     n,
+			Name:       entry.Name(),
+			StartBytes: start * 512,
+			SizeBytes:  size * 512,
+		})
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].Number < parts[j].Number })
+	return parts
+}
+
+// partitionName devuelve el nombre del dispositivo de una partición:
+// sda + 1 = sda1, nvme0n1 + 1 = nvme0n1p1
+func partitionName(disk string, n int) string {
+	if last := disk[len(disk)-1]; last >= '0' && last <= '9' {
+		return disk + "p" + strconv.Itoa(n)
+	}
+	return disk + strconv.Itoa(n)
+}
+
+// mbrTypeCode convierte "0x83" en 0x83 (0 para tipos GPT)
+func mbrTypeCode(t string) uint8 {
+	v, err := strconv.ParseUint(strings.TrimPrefix(t, "0x"), 16, 8)
+	if err != nil || !strings.HasPrefix(t, "0x") {
+		return 0
+	}
+	return uint8(v)
+}
+
+// diskContents resume lo que contiene un disco para decidir rápidamente si
+// se puede borrar: instalaciones de Windows, Linux o macOS y volúmenes
+// cifrados, RAID o LVM
+func diskContents(disk DiskInfo) []string {
+	contents := make([]string, 0)
+	add := func(s string) {
+		contents = appendUnique(contents, s)
+	}
+
+	filesystems := []FilesystemInfo{disk.Filesystem}
+	hasNTFS, hasWindowsParts := false, false
+	for _, p := range disk.Partitions {
+		filesystems = append(filesystems, p.Filesystem)
+		switch p.TypeName {
+		case "Microsoft reserved", "Windows recovery environment", "Hidden NTFS WinRE":
+			hasWindowsParts = true
+		case "Apple APFS", "Apple HFS/HFS+":
+			add("macOS")
+		}
+	}
+
+	for _, fs := range filesystems {
+		switch fs.Type {
+		case "ntfs":
+			hasNTFS = true
+		case "bitlocker":
+			hasNTFS = true
+			add("cifrado BitLocker")
+		case "crypto_LUKS":
+			add("cifrado LUKS")
+		case "ext2", "ext3", "ext4", "xfs", "btrfs", "swap":
+			add("Linux")
+		case "LVM2_member":
+			add("LVM")
+		case "linux_raid_member":
+			add("RAID")
+		}
+	}
+	if hasNTFS && (hasWindowsParts || disk.PartitionTable == PartitionTableMBR) {
+		contents = append([]string{"Windows"}, contents...)
+	} else if hasNTFS {
+		add("NTFS")
+	}
+
+	return contents
+}
+
+// trimNul recorta los bytes NUL y espacios de un campo de texto de tamaño fijo
+func trimNul(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return strings.TrimSpace(string(b))
+}
@@ -0,0 +1,126 @@
+package hardware
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Lexharden/hwscan/internal/ids"
+)
+
+// pciIDs devuelve la base de datos de nombres PCI configurada en el detector
+// o, si no hay ninguna, la del sistema/embebida
+func (d *Detector) pciIDs() *ids.Database {
+	if d.PCIIDs != nil {
+		return d.PCIIDs
+	}
+	return ids.PCI()
+}
+
+// pciDevices enumera /sys/bus/pci/devices una única vez; el resultado se
+// comparte entre la sección PCI y la detección de GPUs
+func (d *Detector) pciDevices() ([]PCIDevice, error) {
+	d.pciOnce.Do(func() {
+		d.pci, d.pciErr = d.detectPCI()
+	})
+	return d.pci, d.pciErr
+}
+
+// detectPCI lee todos los dispositivos PCI desde sysfs y resuelve sus nombres
+func (d *Detector) detectPCI() ([]PCIDevice, error) {
+	devices := make([]PCIDevice, 0)
+
+	entries, err := os.ReadDir(d.path("/sys/bus/pci/devices"))
+	if err != nil {
+		return devices, err
+	}
+
+	db := d.pciIDs()
+	for _, entry := range entries {
+		addr := entry.Name()
+		base := "/sys/bus/pci/devices/" + addr
+
+		dev := PCIDevice{Address: addr}
+
+		// class = 0xCCSSPP (clase, subclase, interfaz de programación)
+		classCode, _ := d.readHexID(base + "/class")
+		dev.Class = fmt.Sprintf("%06x", classCode)
+		class, subclass := uint8(classCode>>16), uint8(classCode>>8)
+		dev.ClassName = db.Class(class, subclass)
+
+		vendorID, _ := d.readHexID(base + "/vendor")
+		deviceID, _ := d.readHexID(base + "/device")
+		subVendorID, _ := d.readHexID(base + "/subsystem_vendor")
+		subDeviceID, _ := d.readHexID(base + "/subsystem_device")
+		dev.VendorID = formatPCIID(vendorID)
+		dev.DeviceID = formatPCIID(deviceID)
+		dev.SubVendorID = formatPCIID(subVendorID)
+		dev.SubDeviceID = formatPCIID(subDeviceID)
+
+		dev.Vendor = db.Vendor(uint16(vendorID))
+		dev.Device = db.Device(uint16(vendorID), uint16(deviceID))
+		dev.Subsystem = db.Subsystem(uint16(vendorID), uint16(deviceID),
+			uint16(subVendorID), uint16(subDeviceID))
+		if dev.Subsystem == "" && subVendorID != 0 && subVendorID != vendorID {
+			// Sin entrada de subsistema al menos mostrar el fabricante de la placa
+			dev.Subsystem = db.Vendor(uint16(subVendorID))
+		}
+
+		if rev, err := d.readHexID(base + "/revision"); err == nil {
+			dev.Revision = fmt.Sprintf("%02x", rev)
+		}
+
+		dev.Driver = d.linkBase(base + "/driver")
+		dev.IOMMUGroup = d.linkBase(base + "/iommu_group")
+
+		devices = append(devices, dev)
+	}
+
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].Address < devices[j].Address
+	})
+
+	return devices, nil
+}
+
+// readHexID lee un archivo de sysfs con un valor hexadecimal como "0x10de"
+func (d *Detector) readHexID(p string) (uint32, error) {
+	s, err := d.readString(p)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseUint(strings.TrimPrefix(s, "0x"), 16, 32)
+	return uint32(v), err
+}
+
+// linkBase devuelve el último componente del destino de un enlace simbólico
+// de sysfs (ej: driver -> ../../bus/pci/drivers/nvidia devuelve "nvidia"),
+// o "" si el enlace no existe
+func (d *Detector) linkBase(p string) string {
+	target, err := os.Readlink(d.path(p))
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(target)
+}
+
+// formatPCIID formatea un ID de 16 bits como 4 dígitos hexadecimales
+func formatPCIID(v uint32) string {
+	return fmt.Sprintf("%04x", v&0xFFFF)
+}
+
+// pciDisplayName devuelve el nombre legible de un dispositivo, con los IDs
+// numéricos cuando la base de datos no lo conoce
+func pciDisplayName(dev PCIDevice) string {
+	name := dev.Device
+	if name == "" {
+		name = "Device " + dev.DeviceID
+	}
+	if dev.Vendor != "" {
+		return dev.Vendor + " " + name
+	}
+	return "Vendor " + dev.VendorID + " " + name
+}
@@ -0,0 +1,181 @@
+package hardware
+
+import (
+	"slices"
+	"strings"
+)
+
+// Hipervisores de PlatformInfo.Hypervisor
+const (
+	HypervisorKVM        = "KVM"
+	HypervisorQEMU       = "QEMU"
+	HypervisorVMware     = "VMware"
+	HypervisorHyperV     = "Hyper-V"
+	HypervisorXen        = "Xen"
+	HypervisorVirtualBox = "VirtualBox"
+	HypervisorParallels  = "Parallels"
+	HypervisorBhyve      = "bhyve"
+	HypervisorUnknown    = "unknown" // Hay indicios de virtualización pero no del hipervisor
+)
+
+// Entornos de PlatformInfo.Container
+const (
+	ContainerDocker     = "docker"
+	ContainerPodman     = "podman"
+	ContainerLXC        = "lxc"
+	ContainerNspawn     = "systemd-nspawn"
+	ContainerKubernetes = "kubernetes"
+	ContainerWSL        = "wsl"
+)
+
+// dmiHypervisors relaciona textos de DMI (fabricante, producto, BIOS) con el
+// hipervisor que los publica. Se busca como subcadena, en orden.
+var dmiHypervisors = []struct {
+	match      string
+	hypervisor string
+}{
+	{"VMware", HypervisorVMware},
+	{"innotek", HypervisorVirtualBox},
+	{"VirtualBox", HypervisorVirtualBox},
+	{"Parallels", HypervisorParallels},
+	{"Virtual Machine", HypervisorHyperV}, // Microsoft Corporation / Virtual Machine
+	{"Xen", HypervisorXen},
+	{"Amazon EC2", HypervisorKVM},
+	{"Google Compute Engine", HypervisorKVM},
+	{"OpenStack", HypervisorKVM},
+	{"KVM", HypervisorKVM},
+	{"QEMU", HypervisorQEMU},
+	{"Bochs", HypervisorQEMU},
+	{"BHYVE", HypervisorBhyve},
+}
+
+// cgroupContainers relaciona fragmentos de /proc/1/cgroup con el entorno
+var cgroupContainers = []struct {
+	match     string
+	container string
+}{
+	{"kubepods", ContainerKubernetes},
+	{"/docker", ContainerDocker},
+	{"libpod", ContainerPodman},
+	{"/lxc", ContainerLXC},
+	{"machine.slice", ContainerNspawn},
+}
+
+// detectPlatform determina si hwscan se ejecuta en una máquina virtual o en
+// un contenedor. El hipervisor se deduce de los textos DMI, de la hoja de
+// hipervisor de CPUID (o la flag hypervisor de /proc/cpuinfo) y de
+// /sys/hypervisor; el contenedor, de los archivos que dejan los runtimes y de
+// los cgroups del proceso 1.
+func (d *Detector) detectPlatform(cpu CPUInfo) PlatformInfo {
+	p := PlatformInfo{Evidence: make([]string, 0)}
+
+	// DMI: en un contenedor es la del anfitrión, pero sigue delatando una VM
+	dmi := d.dmiStrings()
+	dmiName := ""
+	for _, s := range dmi {
+		for _, h := range dmiHypervisors {
+			if strings.Contains(s, h.match) {
+				dmiName = h.hypervisor
+				p.Evidence = append(p.Evidence, "DMI: "+s)
+				break
+			}
+		}
+		if dmiName != "" {
+			break
+		}
+	}
+
+	cpuidName := ""
+	if id := cpu.CPUID; id != nil && id.Hypervisor != "" {
+		cpuidName, _, _ = strings.Cut(id.Hypervisor, " (")
+		p.Evidence = append(p.Evidence, "CPUID: "+id.HypervisorVendor)
+	} else if slices.Contains(cpu.Flags, "hypervisor") {
+		p.Evidence = append(p.Evidence, "/proc/cpuinfo: flag hypervisor")
+	}
+
+	sysfsName := ""
+	if typ, err := d.readString("/sys/hypervisor/type"); err == nil && typ != "" {
+		p.Evidence = append(p.Evidence, "/sys/hypervisor/type: "+typ)
+		if typ == "xen" {
+			sysfsName = HypervisorXen
+		}
+	}
+
+	// CPUID identifica el hipervisor real y DMI el modelo de máquina: una VM
+	// de QEMU acelerada por KVM publica "QEMU" en DMI y "KVMKVMKVM" en CPUID
+	switch {
+	case dmiName == HypervisorQEMU && cpuidName == HypervisorKVM:
+		p.Hypervisor = HypervisorKVM
+	case dmiName != "":
+		p.Hypervisor = dmiName
+	case cpuidName != "" && cpuidName != HypervisorUnknown:
+		p.Hypervisor = cpuidName
+	case sysfsName != "":
+		p.Hypervisor = sysfsName
+	case len(p.Evidence) > 0:
+		p.Hypervisor = HypervisorUnknown
+	}
+
+	p.Container = d.detectContainer(&p)
+	p.Virtual = p.Hypervisor != "" || p.Container != ""
+	return p
+}
+
+// detectContainer identifica el runtime de contenedor, o WSL, en el que se
+// ejecuta hwscan. Vacío en un sistema nativo.
+func (d *Detector) detectContainer(p *PlatformInfo) string {
+	if d.exists("/.dockerenv") {
+		p.Evidence = append(p.Evidence, "/.dockerenv")
+		return ContainerDocker
+	}
+	if d.exists("/run/.containerenv") {
+		p.Evidence = append(p.Evidence, "/run/.containerenv")
+		return ContainerPodman
+	}
+	// systemd (y LXC, nspawn, podman) lo dejan escrito al arrancar
+	if c, err := d.readString("/run/systemd/container"); err == nil && c != "" {
+		p.Evidence = append(p.Evidence, "/run/systemd/container: "+c)
+		if c == "oci" {
+			return ContainerDocker
+		}
+		return c
+	}
+	if cgroup, err := d.readString("/proc/1/cgroup"); err == nil {
+		for _, c := range cgroupContainers {
+			if strings.Contains(cgroup, c.match) {
+				p.Evidence = append(p.Evidence, "/proc/1/cgroup: "+c.match)
+				return c.container
+			}
+		}
+	}
+	if release, err := d.readString("/proc/sys/kernel/osrelease"); err == nil {
+		lower := strings.ToLower(release)
+		if strings.Contains(lower, "microsoft") || strings.Contains(lower, "wsl") {
+			p.Evidence = append(p.Evidence, "kernel "+release)
+			return ContainerWSL
+		}
+	}
+	return ""
+}
+
+// dmiStrings devuelve los textos DMI que identifican un hipervisor: desde
+// /sys/class/dmi/id o, si no existe, desde la tabla SMBIOS
+func (d *Detector) dmiStrings() []string {
+	strs := make([]string, 0, 6)
+	for _, f := range []string{"sys_vendor", "product_name", "product_version", "board_vendor", "bios_vendor", "chassis_vendor"} {
+		if v, err := d.readString("/sys/class/dmi/id/" + f); err == nil && v != "" {
+			strs = append(strs, v)
+		}
+	}
+	if len(strs) > 0 {
+		return strs
+	}
+	if table, err := d.readSMBIOS(); err == nil {
+		for _, v := range []string{table.System.Manufacturer, table.System.Product, table.System.Version, table.Baseboard.Manufacturer, table.BIOS.Vendor} {
+			if v != "" {
+				strs = append(strs, v)
+			}
+		}
+	}
+	return strs
+}
@@ -0,0 +1,166 @@
+package hardware
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Plugin describe un detector externo que agrega una sección propia al
+// reporte sin modificar HardwareInfo. Se registra con Register, normalmente
+// desde una función init, y se ejecuta junto a los detectores integrados.
+type Plugin struct {
+	// Name identifica la sección en HardwareInfo.Sections, en Diagnostics y
+	// en -detector-timeouts. No puede coincidir con un detector integrado.
+	Name string
+	// Title es el título de la sección en consola y web (por defecto Name)
+	Title string
+	// Requires lista los detectores (integrados o plugins) que deben terminar
+	// antes. Si alguno falla, el plugin se omite.
+	Requires []string
+	// Detect devuelve los datos de la sección, que deben poder serializarse
+	// a JSON. info nunca es nil: contiene los resultados de los detectores
+	// de Requires (vacío si no declara ninguno) y no debe modificarse.
+	// Devolver nil sin error omite la sección; un pánico se informa como
+	// fallo del plugin sin interrumpir el resto de la detección.
+	Detect func(ctx context.Context, d *Detector, info *HardwareInfo) (any, error)
+}
+
+// ConsoleSection permite a los datos de un plugin definir su presentación en
+// FormatConsole. Si no se implementa se muestra el JSON de la sección.
+type ConsoleSection interface {
+	ConsoleLines() []string
+}
+
+var registry struct {
+	mu      sync.Mutex
+	plugins []Plugin
+}
+
+// Register agrega un plugin al registro global. Entra en pánico si el nombre
+// está vacío, ya está en uso o falta Detect, igual que database/sql.Register.
+func Register(p Plugin) {
+	if p.Name == "" {
+		panic("hardware: Register con nombre vacío")
+	}
+	if p.Detect == nil {
+		panic("hardware: Register sin función Detect para " + p.Name)
+	}
+	if p.Title == "" {
+		p.Title = p.Name
+	}
+
+	for _, spec := range (&Detector{}).builtinDetectors() {
+		if spec.name == p.Name {
+			panic("hardware: Register con nombre de detector integrado " + p.Name)
+		}
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	for _, existing := range registry.plugins {
+		if existing.Name == p.Name {
+			panic("hardware: Register llamado dos veces para " + p.Name)
+		}
+	}
+	p.Requires = append([]string(nil), p.Requires...)
+	registry.plugins = append(registry.plugins, p)
+}
+
+// Plugins devuelve los plugins registrados, en orden de registro
+func Plugins() []Plugin {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	return append([]Plugin(nil), registry.plugins...)
+}
+
+// pluginSpec adapta un plugin al formato de los detectores integrados
+func (d *Detector) pluginSpec(p Plugin) detectorSpec {
+	return detectorSpec{
+		name:   p.Name,
+		label:  p.Title,
+		source: "plugin",
+		deps:   p.Requires,
+		run: func(ctx context.Context, rep *report, view *HardwareInfo) (func(*HardwareInfo), error) {
+			if view == nil {
+				view = &HardwareInfo{Sections: make(map[string]Section)}
+			}
+			data, err := p.Detect(ctx, d, view)
+			if err != nil {
+				return nil, err
+			}
+			if data == nil {
+				rep.skip("sin datos")
+				return nil, nil
+			}
+			return func(info *HardwareInfo) {
+				info.Sections[p.Name] = Section{Title: p.Title, Data: data}
+			}, nil
+		},
+	}
+}
+
+// unresolvable devuelve, por nombre de detector, el motivo por el que no
+// puede ejecutarse: dependencias desconocidas o ciclos entre dependencias.
+// Los detectores que solo dependen de estos se omiten al ejecutarse.
+func unresolvable(specs []detectorSpec) map[string]string {
+	index := make(map[string]int, len(specs))
+	for i, spec := range specs {
+		index[spec.name] = i
+	}
+
+	blocked := make(map[string]string)
+	for _, spec := range specs {
+		for _, dep := range spec.deps {
+			if _, ok := index[dep]; !ok {
+				blocked[spec.name] = fmt.Sprintf("dependencia desconocida: %s", dep)
+			}
+		}
+	}
+
+	// Búsqueda en profundidad: 1 = en la pila actual, 2 = terminado
+	state := make([]int, len(specs))
+	var stack []int
+	var visit func(i int)
+	visit = func(i int) {
+		state[i] = 1
+		stack = append(stack, i)
+		for _, dep := range specs[i].deps {
+			j, ok := index[dep]
+			if !ok {
+				continue
+			}
+			switch state[j] {
+			case 0:
+				visit(j)
+			case 1:
+				// Ciclo: todos los detectores de la pila desde j hasta i
+				start := len(stack) - 1
+				for stack[start] != j {
+					start--
+				}
+				cycle := make([]string, 0, len(stack)-start+1)
+				for _, k := range stack[start:] {
+					cycle = append(cycle, specs[k].name)
+				}
+				cycle = append(cycle, dep)
+				reason := "dependencia circular: " + strings.Join(cycle, " -> ")
+				for _, k := range stack[start:] {
+					if _, ok := blocked[specs[k].name]; !ok {
+						blocked[specs[k].name] = reason
+					}
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[i] = 2
+	}
+	for i := range specs {
+		if state[i] == 0 {
+			visit(i)
+		}
+	}
+
+	return blocked
+}
@@ -0,0 +1,133 @@
+package hardware
+
+import (
+	"errors"
+	"os"
+	"strconv"
+)
+
+// DefaultBatteryHealthThreshold es la salud mínima de batería (capacidad a
+// plena carga frente a la de diseño, en %) por debajo de la cual se marca
+const DefaultBatteryHealthThreshold = 80
+
+// Unidades de BatteryInfo.CapacityUnit
+const (
+	CapacityWh  = "Wh"
+	CapacityMAh = "mAh"
+)
+
+// detectPower lee baterías y adaptadores de corriente desde
+// /sys/class/power_supply. Las baterías de periféricos (ratones, teclados
+// inalámbricos: scope "Device") se ignoran.
+func (d *Detector) detectPower(rep *report) (PowerInfo, error) {
+	threshold := d.BatteryHealthThreshold
+	if threshold <= 0 {
+		threshold = DefaultBatteryHealthThreshold
+	}
+	power := PowerInfo{
+		Adapters:        make([]PowerAdapter, 0),
+		Batteries:       make([]BatteryInfo, 0),
+		HealthThreshold: threshold,
+	}
+
+	entries, err := os.ReadDir(d.path("/sys/class/power_supply"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			rep.skip("sin fuentes de alimentación: /sys/class/power_supply no existe")
+			return power, nil
+		}
+		return power, err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		base := "/sys/class/power_supply/" + name
+		typ, _ := d.readString(base + "/type")
+
+		switch typ {
+		case "Battery":
+			if scope, _ := d.readString(base + "/scope"); scope == "Device" {
+				continue
+			}
+			bat := d.readBattery(name)
+			bat.BelowThreshold = bat.HealthPercent >= 0 && bat.HealthPercent < threshold
+			if bat.Present && bat.HealthPercent < 0 {
+				rep.note("batería %s: el firmware no informa la capacidad de diseño", name)
+			}
+			power.Batteries = append(power.Batteries, bat)
+		case "Mains", "USB", "UPS", "Wireless":
+			adapter := PowerAdapter{Name: name, Type: typ}
+			adapter.Online = d.readFirst(base+"/online") == "1"
+			power.OnAC = power.OnAC || adapter.Online
+			power.Adapters = append(power.Adapters, adapter)
+		}
+	}
+
+	return power, nil
+}
+
+// readBattery lee una batería. Los drivers informan la capacidad en energía
+// (energy_*, µWh) o en carga (charge_*, µAh); la carga se convierte a Wh con
+// la tensión de diseño cuando se conoce.
+func (d *Detector) readBattery(name string) BatteryInfo {
+	base := "/sys/class/power_supply/" + name
+	bat := BatteryInfo{
+		Name:            name,
+		ChargePercent:   -1,
+		HealthPercent:   -1,
+		WearPercent:     -1,
+		CycleCount:      -1,
+		CapacityUnit:    CapacityWh,
+		Present:         d.readFirst(base+"/present") != "0",
+		Manufacturer:    d.readFirst(base + "/manufacturer"),
+		Model:           d.readFirst(base + "/model_name"),
+		Serial:          d.readFirst(base + "/serial_number"),
+		Technology:      d.readFirst(base + "/technology"),
+		Status:          d.readFirst(base + "/status"),
+		CapacityLevel:   d.readFirst(base + "/capacity_level"),
+		DesignVoltageV:  d.readMicro(base + "/voltage_min_design"),
+		VoltageNowV:     d.readMicro(base + "/voltage_now"),
+		DesignCapacity:  d.readMicro(base + "/energy_full_design"),
+		FullCapacity:    d.readMicro(base + "/energy_full"),
+		CurrentCapacity: d.readMicro(base + "/energy_now"),
+	}
+
+	if v, err := d.readString(base + "/capacity"); err == nil {
+		bat.ChargePercent, _ = strconv.Atoi(v)
+	}
+	if v, err := d.readString(base + "/cycle_count"); err == nil {
+		// Muchos firmwares informan 0 cuando no llevan la cuenta
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			bat.CycleCount = n
+		}
+	}
+
+	if bat.DesignCapacity == 0 {
+		design := d.readMicro(base + "/charge_full_design")
+		full := d.readMicro(base + "/charge_full")
+		now := d.readMicro(base + "/charge_now")
+		if v := bat.DesignVoltageV; v > 0 {
+			bat.DesignCapacity, bat.FullCapacity, bat.CurrentCapacity = design*v, full*v, now*v
+		} else {
+			bat.CapacityUnit = CapacityMAh
+			bat.DesignCapacity, bat.FullCapacity, bat.CurrentCapacity = design*1000, full*1000, now*1000
+		}
+	}
+
+	if bat.DesignCapacity > 0 && bat.FullCapacity > 0 {
+		bat.HealthPercent = bat.FullCapacity / bat.DesignCapacity * 100
+		bat.WearPercent = max(0, 100-bat.HealthPercent)
+	}
+	return bat
+}
+
+// readMicro lee un valor de power_supply en micro-unidades (µWh, µAh, µV) y
+// lo devuelve en unidades (Wh, Ah, V). 0 si no existe.
+func (d *Detector) readMicro(p string) float64 {
+	s, err := d.readString(p)
+	if err != nil {
+		return 0
+	}
+	v, _ := strconv.ParseFloat(s, 64)
+	return v / 1e6
+}
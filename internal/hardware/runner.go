@@ -0,0 +1,107 @@
+package hardware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// CommandRunner ejecuta comandos externos (dmidecode, lspci, nvidia-smi) y
+// devuelve su salida estándar. Permite sustituir la ejecución real por
+// salidas grabadas para reproducir reportes de campo.
+type CommandRunner interface {
+	Run(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+// ExecRunner ejecuta los comandos en el sistema local
+type ExecRunner struct{}
+
+// Run ejecuta el comando y devuelve su salida estándar. Si ctx se cancela o
+// vence se mata el grupo de procesos completo, incluidos los hijos que haya
+// lanzado el comando.
+func (ExecRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	// No esperar indefinidamente a que los nietos cierren stdout
+	cmd.WaitDelay = time.Second
+	return cmd.Output()
+}
+
+// RecordingRunner ejecuta los comandos con Runner y guarda su salida en Dir
+// con el formato que entiende ReplayRunner:
+//   - <comando>.out: salida estándar (se guarda siempre, aunque esté vacía)
+//   - <comando>.err: mensaje de error (solo si el comando falló)
+type RecordingRunner struct {
+	Runner CommandRunner
+	Dir    string
+}
+
+// Run ejecuta el comando y graba su resultado. Los errores al escribir la
+// grabación no afectan al resultado del comando.
+func (r *RecordingRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	out, err := r.Runner.Run(ctx, name, args...)
+
+	base := filepath.Join(r.Dir, CommandFileName(name, args...))
+	os.WriteFile(base+".out", out, 0644)
+	if err != nil {
+		os.WriteFile(base+".err", []byte(err.Error()+"\n"), 0644)
+	}
+
+	return out, err
+}
+
+// ReplayRunner sirve la salida grabada por RecordingRunner en Dir en lugar de
+// ejecutar los comandos
+type ReplayRunner struct {
+	Dir string
+}
+
+// Run devuelve la salida grabada del comando. Si no hay grabación se comporta
+// como si el comando no estuviera instalado.
+func (r ReplayRunner) Run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	base := filepath.Join(r.Dir, CommandFileName(name, args...))
+
+	out, err := os.ReadFile(base + ".out")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%s: sin salida grabada: %w", name, exec.ErrNotFound)
+		}
+		return nil, err
+	}
+
+	if msg, err := os.ReadFile(base + ".err"); err == nil {
+		return out, errors.New(strings.TrimSpace(string(msg)))
+	}
+
+	return out, nil
+}
+
+// CommandFileName genera el nombre de archivo (sin extensión) con el que se
+// graba un comando, ej: "dmidecode_-t_memory"
+func CommandFileName(name string, args ...string) string {
+	parts := append([]string{name}, args...)
+	joined := strings.Join(parts, "_")
+
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r == '.', r == '-', r == '=', r == '_':
+			return r
+		}
+		return '_'
+	}, joined)
+}
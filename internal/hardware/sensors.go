@@ -0,0 +1,250 @@
+package hardware
+
+import (
+	"context"
+	"errors"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tipos de SensorReading.Type
+const (
+	SensorTemperature = "temperature"
+	SensorFan         = "fan"
+	SensorVoltage     = "voltage"
+	SensorCurrent     = "current"
+	SensorPower       = "power"
+)
+
+// hwmonInput reconoce los atributos de lectura de hwmon: temp1_input,
+// fan2_input, in0_input, curr1_input, power1_input o power1_average
+var hwmonInput = regexp.MustCompile(`^(temp|fan|in|curr|power)(\d+)_(input|average)$`)
+
+// hwmonKinds relaciona el prefijo de atributo de hwmon con el tipo de
+// sensor, su unidad y el divisor que convierte el valor de sysfs (milésimas
+// o millonésimas) a esa unidad
+var hwmonKinds = map[string]struct {
+	kind    string
+	unit    string
+	divisor float64
+	order   int
+}{
+	"temp":  {SensorTemperature, "°C", 1000, 0},
+	"fan":   {SensorFan, "RPM", 1, 1},
+	"in":    {SensorVoltage, "V", 1000, 2},
+	"curr":  {SensorCurrent, "A", 1000, 3},
+	"power": {SensorPower, "W", 1000000, 4},
+}
+
+// detectSensors lee todos los sensores de /sys/class/hwmon agrupados por chip
+func (d *Detector) detectSensors(rep *report) ([]SensorChip, error) {
+	chips, err := d.readHwmon()
+	if errors.Is(err, os.ErrNotExist) {
+		// Sin hwmon (ej: VMs y contenedores)
+		rep.skip("sin sensores: /sys/class/hwmon no existe")
+		return chips, nil
+	}
+	if err == nil && len(chips) == 0 {
+		rep.note("ningún driver hwmon cargado")
+	}
+	return chips, err
+}
+
+// readHwmon lee una muestra de todos los chips hwmon
+func (d *Detector) readHwmon() ([]SensorChip, error) {
+	chips := make([]SensorChip, 0)
+
+	entries, err := os.ReadDir(d.path("/sys/class/hwmon"))
+	if err != nil {
+		return chips, err
+	}
+	for _, entry := range entries {
+		chip := d.readHwmonChip(entry.Name())
+		if len(chip.Readings) > 0 {
+			chips = append(chips, chip)
+		}
+	}
+
+	sort.Slice(chips, func(i, j int) bool {
+		return hwmonIndex(chips[i].Device) < hwmonIndex(chips[j].Device)
+	})
+	return chips, nil
+}
+
+// readHwmonChip lee los sensores de /sys/class/hwmon/<name>. Los drivers
+// antiguos publican los atributos en device/ en lugar del propio directorio.
+func (d *Detector) readHwmonChip(name string) SensorChip {
+	base := "/sys/class/hwmon/" + name
+	chip := SensorChip{Device: name, Readings: make([]SensorReading, 0)}
+
+	chip.Name, _ = d.readString(base + "/name")
+	if chip.Name == "" {
+		if v, err := d.readString(base + "/device/name"); err == nil {
+			chip.Name = v
+			base += "/device"
+		}
+	}
+	chip.Parent = d.linkBase("/sys/class/hwmon/" + name + "/device")
+
+	files, err := os.ReadDir(d.path(base))
+	if err != nil {
+		return chip
+	}
+	seen := make(map[string]bool)
+	for _, f := range files {
+		m := hwmonInput.FindStringSubmatch(f.Name())
+		if m == nil {
+			continue
+		}
+		attr := m[1] + m[2]
+		if seen[attr] {
+			continue // power1_input y power1_average
+		}
+		seen[attr] = true
+
+		if r, ok := d.readHwmonReading(base, name, m[1], attr, f.Name()); ok {
+			chip.Readings = append(chip.Readings, r)
+		}
+	}
+
+	sort.Slice(chip.Readings, func(i, j int) bool {
+		a, b := chip.Readings[i], chip.Readings[j]
+		ka, kb := hwmonKinds[hwmonPrefix(a.ID)], hwmonKinds[hwmonPrefix(b.ID)]
+		if ka.order != kb.order {
+			return ka.order < kb.order
+		}
+		return hwmonIndex(a.ID) < hwmonIndex(b.ID)
+	})
+	return chip
+}
+
+// readHwmonReading lee un sensor: valor, etiqueta, umbrales y alarmas.
+// ok es false si el valor no se puede leer (sensor desconectado o en fallo).
+func (d *Detector) readHwmonReading(base, device, prefix, attr, input string) (SensorReading, bool) {
+	kind := hwmonKinds[prefix]
+	value := func(suffix string) (float64, bool) {
+		s, err := d.readString(base + "/" + attr + "_" + suffix)
+		if err != nil {
+			return 0, false
+		}
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, false
+		}
+		return v / kind.divisor, true
+	}
+	flag := func(suffix string) bool {
+		s, err := d.readString(base + "/" + attr + "_" + suffix)
+		return err == nil && s != "" && s != "0"
+	}
+
+	raw, err := d.readString(base + "/" + input)
+	if err != nil {
+		return SensorReading{}, false
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil || flag("fault") {
+		return SensorReading{}, false
+	}
+
+	r := SensorReading{
+		ID:    device + "/" + attr,
+		Type:  kind.kind,
+		Unit:  kind.unit,
+		Value: v / kind.divisor,
+	}
+	r.Label, _ = d.readString(base + "/" + attr + "_label")
+	if r.Label == "" {
+		r.Label = attr
+	}
+	r.Min, _ = value("min")
+	r.Max, _ = value("max")
+	if prefix == "power" {
+		if c, ok := value("cap"); ok && r.Max == 0 {
+			r.Max = c
+		}
+	}
+	r.Crit, _ = value("crit")
+
+	r.Alarm = flag("alarm") || flag("crit_alarm") || flag("max_alarm") || flag("min_alarm") || flag("lcrit_alarm")
+	if r.Crit > 0 && r.Value >= r.Crit {
+		r.Alarm = true
+	}
+	return r, true
+}
+
+// SampleSensors toma samples muestras de todos los sensores hwmon separadas
+// por interval y devuelve la serie de cada sensor con su mínimo, máximo y
+// media. Se detiene antes si el contexto se cancela.
+func (d *Detector) SampleSensors(ctx context.Context, samples int, interval time.Duration) ([]SensorTrend, error) {
+	trends := make([]SensorTrend, 0)
+	index := make(map[string]int)
+
+	for i := 0; i < samples; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return trends, ctx.Err()
+			case <-time.After(interval):
+			}
+		}
+
+		chips, err := d.readHwmon()
+		if errors.Is(err, os.ErrNotExist) {
+			// Sin hwmon la serie queda vacía, igual que en detectSensors
+			return trends, nil
+		}
+		if err != nil {
+			return trends, err
+		}
+		for _, chip := range chips {
+			for _, r := range chip.Readings {
+				n, ok := index[r.ID]
+				if !ok {
+					n = len(trends)
+					index[r.ID] = n
+					trends = append(trends, SensorTrend{
+						ID: r.ID, Chip: chip.Name, Label: r.Label, Type: r.Type, Unit: r.Unit,
+						Crit: r.Crit, Min: r.Value, Max: r.Value, Values: make([]float64, 0, samples),
+					})
+				}
+				t := &trends[n]
+				t.Values = append(t.Values, r.Value)
+				t.Min = min(t.Min, r.Value)
+				t.Max = max(t.Max, r.Value)
+				t.Alarm = t.Alarm || r.Alarm
+			}
+		}
+	}
+
+	for i := range trends {
+		sum := 0.0
+		for _, v := range trends[i].Values {
+			sum += v
+		}
+		trends[i].Avg = sum / float64(len(trends[i].Values))
+	}
+	return trends, nil
+}
+
+// hwmonIndex extrae el número final de "hwmon3" o "hwmon0/temp12" para
+// ordenar numéricamente
+func hwmonIndex(s string) int {
+	end := len(s)
+	start := end
+	for start > 0 && s[start-1] >= '0' && s[start-1] <= '9' {
+		start--
+	}
+	n, _ := strconv.Atoi(s[start:end])
+	return n
+}
+
+// hwmonPrefix devuelve el prefijo de atributo ("temp") de un ID "hwmon0/temp1"
+func hwmonPrefix(id string) string {
+	_, attr, _ := strings.Cut(id, "/")
+	return strings.TrimRight(attr, "0123456789")
+}
@@ -0,0 +1,260 @@
+package hardware
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// Veredictos de DiskHealth.Verdict
+const (
+	HealthPassed = "PASSED"
+	HealthFailed = "FAILED"
+)
+
+// Tamaño de las páginas SMART de ATA y del log SMART/Health de NVMe
+const smartPageSize = 512
+
+// Atributos SMART de ATA que se resumen en DiskHealth
+const (
+	ataAttrReallocated   = 5
+	ataAttrPowerOnHours  = 9
+	ataAttrPowerCycles   = 12
+	ataAttrWearLeveling  = 177 // Samsung: valor normalizado = vida restante
+	ataAttrReportedUncor = 187
+	ataAttrAirflowTemp   = 190
+	ataAttrTemperature   = 194
+	ataAttrPending       = 197
+	ataAttrOfflineUncor  = 198
+	ataAttrLifeLeft      = 202 // Crucial/Micron: porcentaje de vida restante
+	ataAttrSSDLifeLeft   = 231
+	ataAttrMediaWearout  = 233 // Intel: valor normalizado = vida restante
+)
+
+// ataAttributeNames son los nombres de los atributos SMART más habituales
+var ataAttributeNames = map[uint8]string{
+	1:   "Raw_Read_Error_Rate",
+	3:   "Spin_Up_Time",
+	4:   "Start_Stop_Count",
+	5:   "Reallocated_Sector_Ct",
+	7:   "Seek_Error_Rate",
+	9:   "Power_On_Hours",
+	10:  "Spin_Retry_Count",
+	12:  "Power_Cycle_Count",
+	177: "Wear_Leveling_Count",
+	183: "Runtime_Bad_Block",
+	184: "End-to-End_Error",
+	187: "Reported_Uncorrect",
+	188: "Command_Timeout",
+	190: "Airflow_Temperature_Cel",
+	194: "Temperature_Celsius",
+	196: "Reallocated_Event_Count",
+	197: "Current_Pending_Sector",
+	198: "Offline_Uncorrectable",
+	199: "UDMA_CRC_Error_Count",
+	202: "Percent_Lifetime_Remain",
+	231: "SSD_Life_Left",
+	233: "Media_Wearout_Indicator",
+	241: "Total_LBAs_Written",
+	242: "Total_LBAs_Read",
+}
+
+// parseATASmart interpreta las páginas SMART READ DATA y SMART READ
+// THRESHOLDS (512 bytes cada una, 30 entradas de 12 bytes desde el offset 2).
+// thresholds puede ser nil; en ese caso no se marca ningún atributo como
+// fallando ni se deduce el veredicto.
+func parseATASmart(data, thresholds []byte) (*DiskHealth, error) {
+	if len(data) < smartPageSize {
+		return nil, fmt.Errorf("página SMART de %d bytes (se esperaban %d)", len(data), smartPageSize)
+	}
+	if thresholds != nil && len(thresholds) < smartPageSize {
+		return nil, fmt.Errorf("página de umbrales SMART de %d bytes (se esperaban %d)", len(thresholds), smartPageSize)
+	}
+
+	limits := make(map[uint8]uint8)
+	for i := 0; thresholds != nil && i < 30; i++ {
+		entry := thresholds[2+i*12 : 2+(i+1)*12]
+		if entry[0] != 0 {
+			limits[entry[0]] = entry[1]
+		}
+	}
+
+	health := &DiskHealth{
+		Source:         "ata",
+		PercentageUsed: -1,
+		AvailableSpare: -1,
+		Attributes:     make([]SmartAttribute, 0),
+	}
+	failing := false
+	for i := 0; i < 30; i++ {
+		entry := data[2+i*12 : 2+(i+1)*12]
+		id := entry[0]
+		if id == 0 {
+			continue
+		}
+
+		attr := SmartAttribute{
+			ID:        id,
+			Name:      ataAttributeNames[id],
+			PreFail:   binary.LittleEndian.Uint16(entry[1:3])&0x01 != 0,
+			Value:     entry[3],
+			Worst:     entry[4],
+			Threshold: limits[id],
+			Raw:       uint64(entry[5]) | uint64(entry[6])<<8 | uint64(entry[7])<<16 | uint64(entry[8])<<24 | uint64(entry[9])<<32 | uint64(entry[10])<<40,
+		}
+		if attr.Name == "" {
+			attr.Name = fmt.Sprintf("Unknown_Attribute_%d", id)
+		}
+		// Un umbral 0 indica que el atributo nunca falla
+		attr.Failing = attr.Threshold != 0 && attr.Value <= attr.Threshold
+		if attr.Failing && attr.PreFail {
+			failing = true
+		}
+		health.Attributes = append(health.Attributes, attr)
+
+		switch id {
+		case ataAttrReallocated:
+			health.ReallocatedSectors = attr.Raw
+		case ataAttrPowerOnHours:
+			// Los 32 bits altos los usan algunos fabricantes para minutos/segundos
+			health.PowerOnHours = attr.Raw & 0xFFFFFFFF
+		case ataAttrPowerCycles:
+			health.PowerCycles = attr.Raw
+		case ataAttrReportedUncor, ataAttrOfflineUncor:
+			health.UncorrectableErrors += attr.Raw & 0xFFFFFFFF
+		case ataAttrPending:
+			health.PendingSectors = attr.Raw
+		case ataAttrTemperature:
+			health.TemperatureC = int(attr.Raw & 0xFF)
+		case ataAttrAirflowTemp:
+			if health.TemperatureC == 0 {
+				health.TemperatureC = int(attr.Raw & 0xFF)
+			}
+		case ataAttrWearLeveling, ataAttrLifeLeft, ataAttrSSDLifeLeft, ataAttrMediaWearout:
+			if health.PercentageUsed < 0 && attr.Value <= 100 {
+				health.PercentageUsed = 100 - int(attr.Value)
+			}
+		}
+	}
+
+	if thresholds != nil {
+		health.Verdict = HealthPassed
+		if failing {
+			health.Verdict = HealthFailed
+		}
+	}
+
+	return health, nil
+}
+
+// ataSmartStatus interpreta el sense de SMART RETURN STATUS (ATA PASS-THROUGH
+// con CK_COND): el disco devuelve LBA mid/high 4Fh/C2h si está bien y F4h/2Ch
+// si algún atributo superó su umbral. ok es false si el sense no trae el
+// descriptor de estado ATA.
+func ataSmartStatus(sense []byte) (verdict string, ok bool) {
+	// Sense en formato descriptor (72h) con el descriptor ATA Status Return (09h)
+	if len(sense) < 8 || sense[0]&0x7F != 0x72 {
+		return "", false
+	}
+	end := 8 + int(sense[7])
+	if end > len(sense) {
+		end = len(sense)
+	}
+	for i := 8; i+1 < end; i += 2 + int(sense[i+1]) {
+		if sense[i] != 0x09 || i+14 > end {
+			continue
+		}
+		mid, high := sense[i+9], sense[i+11]
+		switch {
+		case mid == 0x4F && high == 0xC2:
+			return HealthPassed, true
+		case mid == 0xF4 && high == 0x2C:
+			return HealthFailed, true
+		}
+		return "", false
+	}
+	return "", false
+}
+
+// parseNVMeSmartLog interpreta el log SMART/Health Information (log 02h) de
+// NVMe. Los contadores son de 128 bits; se usan los 64 bits bajos.
+func parseNVMeSmartLog(page []byte) (*DiskHealth, error) {
+	if len(page) < smartPageSize {
+		return nil, fmt.Errorf("log SMART NVMe de %d bytes (se esperaban %d)", len(page), smartPageSize)
+	}
+	if allZero(page) {
+		return nil, errors.New("log SMART NVMe vacío")
+	}
+
+	u64 := func(off int) uint64 { return binary.LittleEndian.Uint64(page[off : off+8]) }
+
+	health := &DiskHealth{
+		Source:          "nvme",
+		Verdict:         HealthPassed,
+		CriticalWarning: int(page[0]),
+		AvailableSpare:  int(page[3]),
+		PercentageUsed:  int(page[5]),
+		// Unidades de 1000 sectores de 512 bytes
+		DataWrittenBytes: u64(48) * 512000,
+		PowerCycles:      u64(112),
+		PowerOnHours:     u64(128),
+		UnsafeShutdowns:  u64(144),
+		MediaErrors:      u64(160),
+		Attributes:       make([]SmartAttribute, 0),
+	}
+	if kelvin := int(binary.LittleEndian.Uint16(page[1:3])); kelvin > 0 {
+		health.TemperatureC = kelvin - 273
+	}
+	if health.CriticalWarning != 0 {
+		health.Verdict = HealthFailed
+	}
+
+	return health, nil
+}
+
+// readDiskHealth lee el estado SMART de un disco: log SMART/Health en NVMe
+// y ATA PASS-THROUGH en discos SCSI (sd*). Los errores se anotan en el
+// diagnóstico sin afectar al resto de la información del disco. Cada
+// comando se limita al plazo que le queda al detector y, vencido, se omite
+// SMART para no perder el inventario de discos entero.
+func (d *Detector) readDiskHealth(ctx context.Context, rep *report, disk *DiskInfo) {
+	var (
+		health *DiskHealth
+		err    error
+	)
+	switch {
+	case strings.HasPrefix(disk.Name, "nvme"):
+		health, err = readNVMeSmart(ctx, "/dev/"+disk.Name)
+	case strings.HasPrefix(disk.Name, "sd"):
+		health, err = readATASmart(ctx, "/dev/"+disk.Name)
+	default:
+		// virtio, mmc, etc. no tienen SMART
+		return
+	}
+
+	if err != nil {
+		if ctx.Err() != nil || errors.Is(err, context.DeadlineExceeded) {
+			rep.note("SMART de %s omitido: se agotó el tiempo del detector", disk.Name)
+		} else if os.IsPermission(err) || errors.Is(err, syscall.EPERM) || errors.Is(err, syscall.EACCES) {
+			rep.note("SMART de %s: se requieren privilegios de root", disk.Name)
+		} else {
+			rep.note("SMART de %s no disponible: %v", disk.Name, err)
+		}
+		return
+	}
+	disk.Health = health
+}
+
+// allZero indica si todos los bytes son cero
+func allZero(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,219 @@
+package hardware
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// Constantes de SG_IO (scsi/sg.h) y de ATA PASS-THROUGH(16) (SAT)
+const (
+	sgIO            = 0x2285
+	sgDxferNone     = -1
+	sgDxferFromDev  = -3
+	ataPassThru16   = 0x85
+	ataSmartCmd     = 0xB0
+	ataSmartRead    = 0xD0
+	ataSmartThresh  = 0xD1
+	ataSmartStatusF = 0xDA
+	sgTimeout       = 5 * time.Second
+)
+
+// Constantes del ioctl de comandos admin de NVMe (linux/nvme_ioctl.h)
+const (
+	nvmeIoctlAdminCmd = 0xC0484E41 // _IOWR('N', 0x41, struct nvme_admin_cmd)
+	nvmeGetLogPage    = 0x02
+	nvmeLogSmart      = 0x02
+	nvmeNSIDAll       = 0xFFFFFFFF
+)
+
+// sgIOHdr es struct sg_io_hdr
+type sgIOHdr struct {
+	interfaceID    int32
+	dxferDirection int32
+	cmdLen         uint8
+	mxSbLen        uint8
+	iovecCount     uint16
+	dxferLen       uint32
+	dxferp         unsafe.Pointer
+	cmdp           unsafe.Pointer
+	sbp            unsafe.Pointer
+	timeout        uint32
+	flags          uint32
+	packID         int32
+	usrPtr         unsafe.Pointer
+	status         uint8
+	maskedStatus   uint8
+	msgStatus      uint8
+	sbLenWr        uint8
+	hostStatus     uint16
+	driverStatus   uint16
+	resid          int32
+	duration       uint32
+	info           uint32
+}
+
+// nvmeAdminCmd es struct nvme_passthru_cmd
+type nvmeAdminCmd struct {
+	opcode      uint8
+	flags       uint8
+	rsvd1       uint16
+	nsid        uint32
+	cdw2        uint32
+	cdw3        uint32
+	metadata    uint64
+	addr        uint64
+	metadataLen uint32
+	dataLen     uint32
+	cdw10       uint32
+	cdw11       uint32
+	cdw12       uint32
+	cdw13       uint32
+	cdw14       uint32
+	cdw15       uint32
+	timeoutMS   uint32
+	result      uint32
+}
+
+// ioctlTimeoutMS es el tiempo máximo de un comando al disco: sgTimeout o lo
+// que quede hasta el plazo de ctx si es menos. Con el plazo vencido devuelve
+// el error de ctx, ya que un timeout 0 significa el valor por omisión del
+// kernel (60 s en SG_IO).
+func ioctlTimeoutMS(ctx context.Context) (uint32, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	timeout := sgTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = min(timeout, time.Until(deadline))
+	}
+	if timeout < time.Millisecond {
+		return 0, context.DeadlineExceeded
+	}
+	return uint32(timeout.Milliseconds()), nil
+}
+
+// readATASmart lee las páginas SMART de un disco ATA/SATA (también detrás de
+// puentes USB compatibles con SAT) mediante ATA PASS-THROUGH(16) y consulta
+// SMART RETURN STATUS para el veredicto. Requiere root.
+func readATASmart(ctx context.Context, devPath string) (*DiskHealth, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(devPath, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	fd := f.Fd()
+
+	data := make([]byte, smartPageSize)
+	if _, err := ataSmartCommand(ctx, fd, ataSmartRead, data); err != nil {
+		return nil, err
+	}
+	thresholds := make([]byte, smartPageSize)
+	if _, err := ataSmartCommand(ctx, fd, ataSmartThresh, thresholds); err != nil {
+		thresholds = nil
+	}
+
+	health, err := parseATASmart(data, thresholds)
+	if err != nil {
+		return nil, err
+	}
+
+	// El veredicto del propio disco tiene prioridad sobre el calculado
+	if sense, err := ataSmartCommand(ctx, fd, ataSmartStatusF, nil); err == nil {
+		if verdict, ok := ataSmartStatus(sense); ok {
+			health.Verdict = verdict
+		}
+	}
+
+	return health, nil
+}
+
+// ataSmartCommand envía un subcomando SMART (feature) con ATA PASS-THROUGH(16).
+// Con buf se lee un sector (PIO Data-In); sin buf es un comando sin datos que
+// pide los registros de salida (CK_COND) y devuelve el sense.
+func ataSmartCommand(ctx context.Context, fd uintptr, feature uint8, buf []byte) ([]byte, error) {
+	timeout, err := ioctlTimeoutMS(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cdb := make([]byte, 16)
+	cdb[0] = ataPassThru16
+	cdb[4] = feature
+	cdb[10] = 0x4F // LBA mid
+	cdb[12] = 0xC2 // LBA high
+	cdb[14] = ataSmartCmd
+
+	sense := make([]byte, 32)
+	hdr := sgIOHdr{
+		interfaceID: 'S',
+		cmdLen:      uint8(len(cdb)),
+		mxSbLen:     uint8(len(sense)),
+		cmdp:        unsafe.Pointer(&cdb[0]),
+		sbp:         unsafe.Pointer(&sense[0]),
+		timeout:     timeout,
+	}
+	if buf != nil {
+		cdb[1] = 4 << 1 // Protocolo PIO Data-In
+		cdb[2] = 0x0E   // T_DIR=1 (del disco), BYT_BLOK=1, T_LENGTH=sector count
+		cdb[6] = 1      // Un sector
+		hdr.dxferDirection = sgDxferFromDev
+		hdr.dxferLen = uint32(len(buf))
+		hdr.dxferp = unsafe.Pointer(&buf[0])
+	} else {
+		cdb[1] = 3 << 1 // Protocolo Non-data
+		cdb[2] = 0x20   // CK_COND: devolver los registros en el sense
+		hdr.dxferDirection = sgDxferNone
+	}
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, sgIO, uintptr(unsafe.Pointer(&hdr)))
+	runtime.KeepAlive(cdb)
+	runtime.KeepAlive(sense)
+	runtime.KeepAlive(buf)
+	if errno != 0 {
+		return nil, errno
+	}
+	// Con CK_COND el sense de "recovered error" es la respuesta esperada
+	if buf != nil && (hdr.status != 0 || hdr.hostStatus != 0 || hdr.driverStatus&^0x08 != 0) {
+		return nil, syscall.EIO
+	}
+	return sense[:hdr.sbLenWr], nil
+}
+
+// readNVMeSmart lee el log SMART/Health de un controlador NVMe con el comando
+// admin Get Log Page. Requiere root.
+func readNVMeSmart(ctx context.Context, devPath string) (*DiskHealth, error) {
+	timeout, err := ioctlTimeoutMS(ctx)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(devPath, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	page := make([]byte, smartPageSize)
+	cmd := nvmeAdminCmd{
+		opcode:    nvmeGetLogPage,
+		nsid:      nvmeNSIDAll,
+		addr:      uint64(uintptr(unsafe.Pointer(&page[0]))),
+		dataLen:   uint32(len(page)),
+		cdw10:     uint32(len(page)/4-1)<<16 | nvmeLogSmart, // NUMDL (dwords - 1) y LID
+		timeoutMS: timeout,
+	}
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), nvmeIoctlAdminCmd, uintptr(unsafe.Pointer(&cmd)))
+	runtime.KeepAlive(page)
+	if errno != 0 {
+		return nil, errno
+	}
+
+	return parseNVMeSmartLog(page)
+}
@@ -0,0 +1,262 @@
+package hardware
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// readSmartFixture lee una página SMART de testdata/smart. Son páginas de
+// 512 bytes con la disposición y los valores de smartctl -x de cada modelo
+// (atributos, umbrales y checksum), sin datos identificativos del disco.
+func readSmartFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join("testdata", "smart", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// checkHealth compara los campos resumidos de DiskHealth con los esperados
+func checkHealth(t *testing.T, got, want *DiskHealth) {
+	t.Helper()
+	if got.Verdict != want.Verdict {
+		t.Errorf("Verdict = %q, se esperaba %q", got.Verdict, want.Verdict)
+	}
+	if got.PowerOnHours != want.PowerOnHours {
+		t.Errorf("PowerOnHours = %d, se esperaba %d", got.PowerOnHours, want.PowerOnHours)
+	}
+	if got.PowerCycles != want.PowerCycles {
+		t.Errorf("PowerCycles = %d, se esperaba %d", got.PowerCycles, want.PowerCycles)
+	}
+	if got.ReallocatedSectors != want.ReallocatedSectors {
+		t.Errorf("ReallocatedSectors = %d, se esperaba %d", got.ReallocatedSectors, want.ReallocatedSectors)
+	}
+	if got.PendingSectors != want.PendingSectors {
+		t.Errorf("PendingSectors = %d, se esperaba %d", got.PendingSectors, want.PendingSectors)
+	}
+	if got.UncorrectableErrors != want.UncorrectableErrors {
+		t.Errorf("UncorrectableErrors = %d, se esperaba %d", got.UncorrectableErrors, want.UncorrectableErrors)
+	}
+	if got.PercentageUsed != want.PercentageUsed {
+		t.Errorf("PercentageUsed = %d, se esperaba %d", got.PercentageUsed, want.PercentageUsed)
+	}
+	if got.AvailableSpare != want.AvailableSpare {
+		t.Errorf("AvailableSpare = %d, se esperaba %d", got.AvailableSpare, want.AvailableSpare)
+	}
+	if got.MediaErrors != want.MediaErrors {
+		t.Errorf("MediaErrors = %d, se esperaba %d", got.MediaErrors, want.MediaErrors)
+	}
+	if got.TemperatureC != want.TemperatureC {
+		t.Errorf("TemperatureC = %d, se esperaba %d", got.TemperatureC, want.TemperatureC)
+	}
+	if got.CriticalWarning != want.CriticalWarning {
+		t.Errorf("CriticalWarning = %d, se esperaba %d", got.CriticalWarning, want.CriticalWarning)
+	}
+}
+
+func TestParseATASmart(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		thresholds string
+		want       DiskHealth
+		failing    []uint8
+	}{
+		{
+			// SSD: la vida usada sale del valor normalizado de Wear_Leveling_Count
+			// y la temperatura de Airflow_Temperature_Cel (no hay atributo 194)
+			name: "samsung 850 evo", data: "samsung-850-evo.data", thresholds: "samsung-850-evo.thresholds",
+			want: DiskHealth{
+				Verdict: HealthPassed, PowerOnHours: 21734, PowerCycles: 1187,
+				PercentageUsed: 7, AvailableSpare: -1, TemperatureC: 35,
+			},
+		},
+		{
+			// Power_On_Hours con minutos por encima de los 32 bits bajos del valor bruto
+			name: "seagate con sectores pendientes", data: "seagate-st1000dm003.data", thresholds: "seagate-st1000dm003.thresholds",
+			want: DiskHealth{
+				Verdict: HealthPassed, PowerOnHours: 28411, PowerCycles: 398,
+				ReallocatedSectors: 16, PendingSectors: 8, UncorrectableErrors: 13,
+				PercentageUsed: -1, AvailableSpare: -1, TemperatureC: 38,
+			},
+		},
+		{
+			name: "atributo pre-fail bajo el umbral", data: "wd-failing.data", thresholds: "wd-failing.thresholds",
+			want: DiskHealth{
+				Verdict: HealthFailed, PowerOnHours: 44012, PowerCycles: 88,
+				ReallocatedSectors: 3920, PendingSectors: 211, UncorrectableErrors: 190,
+				PercentageUsed: -1, AvailableSpare: -1, TemperatureC: 38,
+			},
+			failing: []uint8{ataAttrReallocated},
+		},
+		{
+			// Sin umbrales no se deduce veredicto
+			name: "sin umbrales", data: "seagate-st1000dm003.data",
+			want: DiskHealth{
+				PowerOnHours: 28411, PowerCycles: 398,
+				ReallocatedSectors: 16, PendingSectors: 8, UncorrectableErrors: 13,
+				PercentageUsed: -1, AvailableSpare: -1, TemperatureC: 38,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var thresholds []byte
+			if tt.thresholds != "" {
+				thresholds = readSmartFixture(t, tt.thresholds)
+			}
+			got, err := parseATASmart(readSmartFixture(t, tt.data), thresholds)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Source != "ata" {
+				t.Errorf("Source = %q, se esperaba ata", got.Source)
+			}
+			checkHealth(t, got, &tt.want)
+
+			failing := make([]uint8, 0)
+			for _, attr := range got.Attributes {
+				if attr.Failing {
+					failing = append(failing, attr.ID)
+				}
+			}
+			if len(failing) != len(tt.failing) || (len(failing) > 0 && failing[0] != tt.failing[0]) {
+				t.Errorf("atributos fallando = %v, se esperaba %v", failing, tt.failing)
+			}
+		})
+	}
+}
+
+func TestParseATASmartTruncated(t *testing.T) {
+	data := readSmartFixture(t, "samsung-850-evo.data")
+	thresholds := readSmartFixture(t, "samsung-850-evo.thresholds")
+
+	if _, err := parseATASmart(data[:smartPageSize-1], thresholds); err == nil {
+		t.Error("página de datos truncada aceptada")
+	}
+	if _, err := parseATASmart(data, thresholds[:100]); err == nil {
+		t.Error("página de umbrales truncada aceptada")
+	}
+	if _, err := parseATASmart(nil, nil); err == nil {
+		t.Error("página vacía aceptada")
+	}
+}
+
+func TestParseNVMeSmartLog(t *testing.T) {
+	tests := []struct {
+		name        string
+		page        string
+		want        DiskHealth
+		wantWritten uint64
+		wantUnsafe  uint64
+	}{
+		{
+			name: "samsung 970 evo plus", page: "samsung-970-evo-plus.nvme",
+			want: DiskHealth{
+				Verdict: HealthPassed, PowerOnHours: 5872, PowerCycles: 1423,
+				PercentageUsed: 3, AvailableSpare: 100, TemperatureC: 38,
+			},
+			wantWritten: 61289730 * 512000,
+			wantUnsafe:  97,
+		},
+		{
+			// Reserva bajo el umbral (bit 0 del aviso crítico) y sin temperatura
+			name: "aviso crítico", page: "nvme-critical.nvme",
+			want: DiskHealth{
+				Verdict: HealthFailed, PowerOnHours: 5872, PowerCycles: 1423,
+				PercentageUsed: 104, AvailableSpare: 4, MediaErrors: 12, CriticalWarning: 1,
+			},
+			wantWritten: 61289730 * 512000,
+			wantUnsafe:  97,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseNVMeSmartLog(readSmartFixture(t, tt.page))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Source != "nvme" {
+				t.Errorf("Source = %q, se esperaba nvme", got.Source)
+			}
+			checkHealth(t, got, &tt.want)
+			if got.DataWrittenBytes != tt.wantWritten {
+				t.Errorf("DataWrittenBytes = %d, se esperaba %d", got.DataWrittenBytes, tt.wantWritten)
+			}
+			if got.UnsafeShutdowns != tt.wantUnsafe {
+				t.Errorf("UnsafeShutdowns = %d, se esperaba %d", got.UnsafeShutdowns, tt.wantUnsafe)
+			}
+		})
+	}
+}
+
+func TestParseNVMeSmartLogInvalid(t *testing.T) {
+	page := readSmartFixture(t, "samsung-970-evo-plus.nvme")
+	if _, err := parseNVMeSmartLog(page[:256]); err == nil {
+		t.Error("log truncado aceptado")
+	}
+	if _, err := parseNVMeSmartLog(make([]byte, smartPageSize)); err == nil {
+		t.Error("log a cero aceptado")
+	}
+}
+
+func TestATASmartStatus(t *testing.T) {
+	// Sense en formato descriptor con un ATA Status Return de 14 bytes
+	sense := func(mid, high byte) []byte {
+		b := make([]byte, 22)
+		b[0], b[7] = 0x72, 14
+		b[8], b[9] = 0x09, 0x0C
+		b[8+9], b[8+11] = mid, high
+		return b
+	}
+
+	tests := []struct {
+		name  string
+		sense []byte
+		want  string
+		ok    bool
+	}{
+		{"correcto", sense(0x4F, 0xC2), HealthPassed, true},
+		{"umbral superado", sense(0xF4, 0x2C), HealthFailed, true},
+		{"firma desconocida", sense(0x00, 0x00), "", false},
+		{"sense fijo", append([]byte{0x70}, make([]byte, 21)...), "", false},
+		{"descriptor truncado", sense(0x4F, 0xC2)[:16], "", false},
+		{"vacío", nil, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ataSmartStatus(tt.sense)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("ataSmartStatus = (%q, %v), se esperaba (%q, %v)", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestIoctlTimeoutMS(t *testing.T) {
+	if got, err := ioctlTimeoutMS(context.Background()); err != nil || got != 5000 {
+		t.Errorf("sin plazo = (%d, %v), se esperaba 5000", got, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if got, err := ioctlTimeoutMS(ctx); err != nil || got == 0 || got > 1000 {
+		t.Errorf("plazo de 1 s = (%d, %v), se esperaba entre 1 y 1000", got, err)
+	}
+
+	expired, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+	if _, err := ioctlTimeoutMS(expired); err == nil {
+		t.Error("plazo vencido aceptado")
+	}
+	if _, err := readATASmart(expired, "/dev/null"); err == nil {
+		t.Error("SMART leído con el plazo vencido")
+	}
+}
@@ -0,0 +1,478 @@
+package hardware
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Tipos de estructura SMBIOS que decodifica HWSCAN
+const (
+	smbiosTypeBIOS              = 0
+	smbiosTypeSystem            = 1
+	smbiosTypeBaseboard         = 2
+	smbiosTypeChassis           = 3
+	smbiosTypeProcessor         = 4
+	smbiosTypeMemoryArray       = 16
+	smbiosTypeMemoryDevice      = 17
+	smbiosTypeMemoryArrayMapped = 19
+	smbiosTypeEndOfTable        = 127
+)
+
+// smbiosTable contiene las estructuras decodificadas de la tabla SMBIOS/DMI
+type smbiosTable struct {
+	Major, Minor  int
+	BIOS          smbiosBIOS
+	System        smbiosSystem
+	Baseboard     smbiosBaseboard
+	Chassis       smbiosChassis
+	Processors    []smbiosProcessor
+	MemoryArrays  []smbiosMemoryArray
+	MemoryDevices []smbiosMemoryDevice
+	MappedBytes   uint64 // Suma de los rangos de memoria mapeados (tipo 19)
+}
+
+// smbiosBIOS es la estructura tipo 0 (BIOS Information)
+type smbiosBIOS struct {
+	Vendor, Version, ReleaseDate string
+}
+
+// smbiosSystem es la estructura tipo 1 (System Information)
+type smbiosSystem struct {
+	Manufacturer, Product, Version, SerialNumber string
+	UUID, SKU, Family                            string
+}
+
+// smbiosBaseboard es la estructura tipo 2 (Baseboard Information)
+type smbiosBaseboard struct {
+	Manufacturer, Product, Version, SerialNumber, AssetTag string
+}
+
+// smbiosChassis es la estructura tipo 3 (System Enclosure or Chassis)
+type smbiosChassis struct {
+	Manufacturer, Version, SerialNumber, AssetTag string
+	Type                                          int
+}
+
+// smbiosProcessor es la estructura tipo 4 (Processor Information)
+type smbiosProcessor struct {
+	Socket       string
+	Manufacturer string
+	Version      string
+	MaxSpeedMHz  int
+	CurSpeedMHz  int
+	Cores        int
+	Threads      int
+	Populated    bool
+}
+
+// smbiosMemoryArray es la estructura tipo 16 (Physical Memory Array)
+type smbiosMemoryArray struct {
+	Handle          uint16
+	Use             int
+	ErrorCorrection int
+	MaxCapacity     uint64 // en bytes
+	Devices         int
+}
+
+// smbiosMemoryDevice es la estructura tipo 17 (Memory Device)
+type smbiosMemoryDevice struct {
+	ArrayHandle     uint16
+	Size            uint64 // en bytes; 0 = ranura vacía
+	FormFactor      int
+	Locator         string
+	BankLocator     string
+	Type            int
+	Speed           int // MT/s
+	Manufacturer    string
+	SerialNumber    string
+	PartNumber      string
+	Rank            int
+	ConfiguredSpeed int // MT/s
+}
+
+// smbiosStructure es una estructura cruda: área formateada + cadenas
+type smbiosStructure struct {
+	Type      byte
+	Handle    uint16
+	Formatted []byte // incluye la cabecera de 4 bytes
+	Strings   []string
+}
+
+// readSMBIOS lee y decodifica la tabla SMBIOS del firmware una única vez.
+// Requiere permisos de root: los archivos de /sys/firmware/dmi/tables son 0400.
+func (d *Detector) readSMBIOS() (*smbiosTable, error) {
+	d.smbiosOnce.Do(func() {
+		entry, err := os.ReadFile(d.path("/sys/firmware/dmi/tables/smbios_entry_point"))
+		if err != nil {
+			d.smbiosErr = err
+			return
+		}
+		major, minor, err := parseSMBIOSEntryPoint(entry)
+		if err != nil {
+			d.smbiosErr = err
+			return
+		}
+
+		data, err := os.ReadFile(d.path("/sys/firmware/dmi/tables/DMI"))
+		if err != nil {
+			d.smbiosErr = err
+			return
+		}
+		d.smbios, d.smbiosErr = parseSMBIOSTable(data, major, minor)
+	})
+
+	return d.smbios, d.smbiosErr
+}
+
+// parseSMBIOSEntryPoint extrae la versión SMBIOS del punto de entrada
+// de 32 bits ("_SM_") o de 64 bits ("_SM3_")
+func parseSMBIOSEntryPoint(data []byte) (major, minor int, err error) {
+	switch {
+	case bytes.HasPrefix(data, []byte("_SM3_")):
+		if len(data) < 0x18 {
+			return 0, 0, errors.New("punto de entrada SMBIOS 3 truncado")
+		}
+		return int(data[0x07]), int(data[0x08]), nil
+	case bytes.HasPrefix(data, []byte("_SM_")):
+		if len(data) < 0x1F {
+			return 0, 0, errors.New("punto de entrada SMBIOS truncado")
+		}
+		return int(data[0x06]), int(data[0x07]), nil
+	}
+	return 0, 0, errors.New("punto de entrada SMBIOS no reconocido")
+}
+
+// splitSMBIOSStructures divide la tabla DMI en estructuras crudas
+func splitSMBIOSStructures(data []byte) ([]smbiosStructure, error) {
+	var structures []smbiosStructure
+
+	for len(data) >= 4 {
+		length := int(data[1])
+		if length < 4 || length > len(data) {
+			return structures, fmt.Errorf("estructura SMBIOS tipo %d con longitud inválida %d", data[0], length)
+		}
+
+		s := smbiosStructure{
+			Type:      data[0],
+			Handle:    binary.LittleEndian.Uint16(data[2:4]),
+			Formatted: data[:length],
+		}
+
+		// El conjunto de cadenas termina con dos bytes nulos
+		rest := data[length:]
+		end := bytes.Index(rest, []byte{0, 0})
+		if end < 0 {
+			return structures, errors.New("conjunto de cadenas SMBIOS sin terminar")
+		}
+		if end > 0 {
+			for _, str := range bytes.Split(rest[:end], []byte{0}) {
+				s.Strings = append(s.Strings, string(str))
+			}
+		}
+
+		structures = append(structures, s)
+		data = rest[end+2:]
+
+		if s.Type == smbiosTypeEndOfTable {
+			break
+		}
+	}
+
+	return structures, nil
+}
+
+// byteAt devuelve el byte en offset o 0 si la estructura es más corta
+func (s smbiosStructure) byteAt(offset int) int {
+	if offset >= len(s.Formatted) {
+		return 0
+	}
+	return int(s.Formatted[offset])
+}
+
+// wordAt devuelve el WORD little-endian en offset o 0 si no existe
+func (s smbiosStructure) wordAt(offset int) uint16 {
+	if offset+2 > len(s.Formatted) {
+		return 0
+	}
+	return binary.LittleEndian.Uint16(s.Formatted[offset:])
+}
+
+// dwordAt devuelve el DWORD little-endian en offset o 0 si no existe
+func (s smbiosStructure) dwordAt(offset int) uint32 {
+	if offset+4 > len(s.Formatted) {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(s.Formatted[offset:])
+}
+
+// qwordAt devuelve el QWORD little-endian en offset o 0 si no existe
+func (s smbiosStructure) qwordAt(offset int) uint64 {
+	if offset+8 > len(s.Formatted) {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(s.Formatted[offset:])
+}
+
+// stringAt resuelve el índice de cadena (base 1) almacenado en offset
+func (s smbiosStructure) stringAt(offset int) string {
+	idx := s.byteAt(offset)
+	if idx == 0 || idx > len(s.Strings) {
+		return ""
+	}
+	return strings.TrimSpace(s.Strings[idx-1])
+}
+
+// parseSMBIOSTable decodifica las estructuras 0, 1, 2, 3, 4, 16, 17 y 19
+// de una tabla DMI cruda
+func parseSMBIOSTable(data []byte, major, minor int) (*smbiosTable, error) {
+	structures, err := splitSMBIOSStructures(data)
+	if len(structures) == 0 {
+		if err == nil {
+			err = errors.New("tabla SMBIOS vacía")
+		}
+		return nil, err
+	}
+
+	t := &smbiosTable{Major: major, Minor: minor}
+	atLeast := func(maj, min int) bool {
+		return major > maj || (major == maj && minor >= min)
+	}
+
+	for _, s := range structures {
+		switch s.Type {
+		case smbiosTypeBIOS:
+			t.BIOS = smbiosBIOS{
+				Vendor:      s.stringAt(0x04),
+				Version:     s.stringAt(0x05),
+				ReleaseDate: s.stringAt(0x08),
+			}
+
+		case smbiosTypeSystem:
+			t.System = smbiosSystem{
+				Manufacturer: s.stringAt(0x04),
+				Product:      s.stringAt(0x05),
+				Version:      s.stringAt(0x06),
+				SerialNumber: s.stringAt(0x07),
+			}
+			if len(s.Formatted) >= 0x18 {
+				// Desde SMBIOS 2.6 los tres primeros campos son little-endian
+				t.System.UUID = formatSMBIOSUUID(s.Formatted[0x08:0x18], atLeast(2, 6))
+			}
+			t.System.SKU = s.stringAt(0x19)
+			t.System.Family = s.stringAt(0x1A)
+
+		case smbiosTypeBaseboard:
+			t.Baseboard = smbiosBaseboard{
+				Manufacturer: s.stringAt(0x04),
+				Product:      s.stringAt(0x05),
+				Version:      s.stringAt(0x06),
+				SerialNumber: s.stringAt(0x07),
+				AssetTag:     s.stringAt(0x08),
+			}
+
+		case smbiosTypeChassis:
+			t.Chassis = smbiosChassis{
+				Manufacturer: s.stringAt(0x04),
+				Type:         s.byteAt(0x05) & 0x7F, // bit 7 = cerradura
+				Version:      s.stringAt(0x06),
+				SerialNumber: s.stringAt(0x07),
+				AssetTag:     s.stringAt(0x08),
+			}
+
+		case smbiosTypeProcessor:
+			p := smbiosProcessor{
+				Socket:       s.stringAt(0x04),
+				Manufacturer: s.stringAt(0x07),
+				Version:      s.stringAt(0x10),
+				MaxSpeedMHz:  int(s.wordAt(0x14)),
+				CurSpeedMHz:  int(s.wordAt(0x16)),
+				Populated:    s.byteAt(0x18)&0x40 != 0,
+				Cores:        s.byteAt(0x23),
+				Threads:      s.byteAt(0x25),
+			}
+			// Valores 0xFF indican que el conteo real está en los campos de 16 bits
+			if p.Cores == 0xFF {
+				p.Cores = int(s.wordAt(0x2A))
+			}
+			if p.Threads == 0xFF {
+				p.Threads = int(s.wordAt(0x2E))
+			}
+			t.Processors = append(t.Processors, p)
+
+		case smbiosTypeMemoryArray:
+			a := smbiosMemoryArray{
+				Handle:          s.Handle,
+				Use:             s.byteAt(0x05),
+				ErrorCorrection: s.byteAt(0x06),
+				Devices:         int(s.wordAt(0x0D)),
+			}
+			if kb := s.dwordAt(0x07); kb == 0x80000000 {
+				a.MaxCapacity = s.qwordAt(0x0F)
+			} else {
+				a.MaxCapacity = uint64(kb) * 1024
+			}
+			t.MemoryArrays = append(t.MemoryArrays, a)
+
+		case smbiosTypeMemoryDevice:
+			t.MemoryDevices = append(t.MemoryDevices, parseSMBIOSMemoryDevice(s))
+
+		case smbiosTypeMemoryArrayMapped:
+			start, end := uint64(s.dwordAt(0x04))*1024, uint64(s.dwordAt(0x08))*1024+1023
+			if s.dwordAt(0x04) == 0xFFFFFFFF {
+				start, end = s.qwordAt(0x0F), s.qwordAt(0x17)
+			}
+			if end > start {
+				t.MappedBytes += end - start + 1
+			}
+		}
+	}
+
+	return t, nil
+}
+
+// parseSMBIOSMemoryDevice decodifica una estructura tipo 17
+func parseSMBIOSMemoryDevice(s smbiosStructure) smbiosMemoryDevice {
+	m := smbiosMemoryDevice{
+		ArrayHandle:     s.wordAt(0x04),
+		FormFactor:      s.byteAt(0x0E),
+		Locator:         s.stringAt(0x10),
+		BankLocator:     s.stringAt(0x11),
+		Type:            s.byteAt(0x12),
+		Speed:           int(s.wordAt(0x15)),
+		Manufacturer:    s.stringAt(0x17),
+		SerialNumber:    s.stringAt(0x18),
+		PartNumber:      s.stringAt(0x1A),
+		Rank:            s.byteAt(0x1B) & 0x0F,
+		ConfiguredSpeed: int(s.wordAt(0x20)),
+	}
+
+	// Tamaño: 0 = vacío, 0xFFFF = desconocido, 0x7FFF = ver tamaño extendido.
+	// El bit 15 indica unidades de KB en lugar de MB.
+	switch size := s.wordAt(0x0C); {
+	case size == 0 || size == 0xFFFF:
+	case size == 0x7FFF:
+		m.Size = uint64(s.dwordAt(0x1C)&0x7FFFFFFF) << 20
+	case size&0x8000 != 0:
+		m.Size = uint64(size&0x7FFF) << 10
+	default:
+		m.Size = uint64(size) << 20
+	}
+
+	// Velocidades 0xFFFF indican que el valor está en los campos extendidos (3.3+)
+	if m.Speed == 0xFFFF {
+		m.Speed = int(s.dwordAt(0x54))
+	}
+	if m.ConfiguredSpeed == 0xFFFF {
+		m.ConfiguredSpeed = int(s.dwordAt(0x58))
+	}
+
+	return m
+}
+
+// formatSMBIOSUUID formatea los 16 bytes del UUID de sistema
+func formatSMBIOSUUID(b []byte, littleEndian bool) string {
+	u := make([]byte, 16)
+	copy(u, b)
+	if littleEndian {
+		u[0], u[1], u[2], u[3] = u[3], u[2], u[1], u[0]
+		u[4], u[5] = u[5], u[4]
+		u[6], u[7] = u[7], u[6]
+	}
+	return fmt.Sprintf("%X-%X-%X-%X-%X", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
+
+// smbiosMemoryTypes mapea el campo Memory Type (tipo 17) a su nombre
+var smbiosMemoryTypes = map[int]string{
+	0x01: "Other", 0x02: "Unknown", 0x03: "DRAM", 0x04: "EDRAM", 0x05: "VRAM",
+	0x06: "SRAM", 0x07: "RAM", 0x08: "ROM", 0x09: "Flash", 0x0A: "EEPROM",
+	0x0B: "FEPROM", 0x0C: "EPROM", 0x0D: "CDRAM", 0x0E: "3DRAM", 0x0F: "SDRAM",
+	0x10: "SGRAM", 0x11: "RDRAM", 0x12: "DDR", 0x13: "DDR2", 0x14: "DDR2 FB-DIMM",
+	0x18: "DDR3", 0x19: "FBD2", 0x1A: "DDR4", 0x1B: "LPDDR", 0x1C: "LPDDR2",
+	0x1D: "LPDDR3", 0x1E: "LPDDR4", 0x1F: "Logical non-volatile device",
+	0x20: "HBM", 0x21: "HBM2", 0x22: "DDR5", 0x23: "LPDDR5", 0x24: "HBM3",
+}
+
+// smbiosFormFactors mapea el campo Form Factor (tipo 17) a su nombre
+var smbiosFormFactors = map[int]string{
+	0x01: "Other", 0x02: "Unknown", 0x03: "SIMM", 0x04: "SIP", 0x05: "Chip",
+	0x06: "DIP", 0x07: "ZIP", 0x08: "Proprietary Card", 0x09: "DIMM",
+	0x0A: "TSOP", 0x0B: "Row Of Chips", 0x0C: "RIMM", 0x0D: "SODIMM",
+	0x0E: "SRIMM", 0x0F: "FB-DIMM", 0x10: "Die",
+}
+
+// smbiosErrorCorrection mapea el campo Error Correction Type (tipo 16)
+var smbiosErrorCorrection = map[int]string{
+	0x01: "Other", 0x02: "Unknown", 0x03: "None", 0x04: "Parity",
+	0x05: "Single-bit ECC", 0x06: "Multi-bit ECC", 0x07: "CRC",
+}
+
+// chassisTypes mapea el tipo de chasis SMBIOS (tipo 3, también expuesto en
+// /sys/class/dmi/id/chassis_type) a su nombre
+var chassisTypes = map[int]string{
+	1: "Other", 2: "Unknown", 3: "Desktop", 4: "Low Profile Desktop",
+	5: "Pizza Box", 6: "Mini Tower", 7: "Tower", 8: "Portable", 9: "Laptop",
+	10: "Notebook", 11: "Hand Held", 12: "Docking Station", 13: "All In One",
+	14: "Sub Notebook", 15: "Space-saving", 16: "Lunch Box",
+	17: "Main Server Chassis", 18: "Expansion Chassis", 19: "Sub Chassis",
+	20: "Bus Expansion Chassis", 21: "Peripheral Chassis", 22: "RAID Chassis",
+	23: "Rack Mount Chassis", 24: "Sealed-case PC", 25: "Multi-system",
+	26: "CompactPCI", 27: "AdvancedTCA", 28: "Blade", 29: "Blade Enclosure",
+	30: "Tablet", 31: "Convertible", 32: "Detachable", 33: "IoT Gateway",
+	34: "Embedded PC", 35: "Mini PC", 36: "Stick PC",
+}
+
+// smbiosMemoryModules convierte los dispositivos tipo 17 instalados en
+// MemoryModule, ignorando los que no pertenecen a la memoria del sistema
+func smbiosMemoryModules(t *smbiosTable) []MemoryModule {
+	// Arrays con Use = 0x03 (System memory)
+	systemArrays := make(map[uint16]bool)
+	for _, a := range t.MemoryArrays {
+		if a.Use == 0x03 {
+			systemArrays[a.Handle] = true
+		}
+	}
+
+	modules := make([]MemoryModule, 0)
+	for _, dev := range t.MemoryDevices {
+		if dev.Size == 0 {
+			continue
+		}
+		if len(systemArrays) > 0 && !systemArrays[dev.ArrayHandle] {
+			continue
+		}
+
+		module := MemoryModule{
+			Size:         formatModuleSize(dev.Size),
+			Type:         smbiosMemoryTypes[dev.Type],
+			Locator:      dev.Locator,
+			Manufacturer: dev.Manufacturer,
+			PartNumber:   dev.PartNumber,
+			SerialNumber: dev.SerialNumber,
+			FormFactor:   smbiosFormFactors[dev.FormFactor],
+			Rank:         dev.Rank,
+		}
+		if dev.Speed > 0 {
+			module.Speed = fmt.Sprintf("%d MT/s", dev.Speed)
+		}
+		if dev.ConfiguredSpeed > 0 {
+			module.ConfiguredSpeed = fmt.Sprintf("%d MT/s", dev.ConfiguredSpeed)
+		}
+
+		modules = append(modules, module)
+	}
+
+	return modules
+}
+
+// formatModuleSize formatea el tamaño de un módulo como lo hace dmidecode
+// ("16 GB", "512 MB")
+func formatModuleSize(b uint64) string {
+	if b >= 1<<30 && b%(1<<30) == 0 {
+		return fmt.Sprintf("%d GB", b>>30)
+	}
+	return fmt.Sprintf("%d MB", b>>20)
+}
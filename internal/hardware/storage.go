@@ -0,0 +1,251 @@
+package hardware
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// mdstatProgress extrae la operación en curso y su avance de /proc/mdstat
+// ("resync = 12.6% (...) finish=80.1min speed=...")
+var mdstatProgress = regexp.MustCompile(`(resync|recovery|reshape|check|repair)\s*=\s*([0-9.]+)%.*?finish=(\S+)`)
+
+// mdstatEntry es lo que /proc/mdstat añade a sysfs para un array md
+type mdstatEntry struct {
+	status    string // [UU_]
+	operation string
+	progress  float64
+	finish    string
+}
+
+// detectStorage describe la topología de almacenamiento: arrays md desde
+// /sys/block/md*/md y /proc/mdstat, y mapeos device-mapper (LVM, dm-crypt,
+// multipath) desde /sys/block/dm-*. Cada entrada se enlaza con los discos
+// físicos de HardwareInfo.Disks siguiendo slaves y particiones.
+func (d *Detector) detectStorage() (StorageTopology, error) {
+	topo := StorageTopology{
+		RAID:     make([]RAIDArray, 0),
+		Mappings: make([]DeviceMapping, 0),
+	}
+
+	entries, err := os.ReadDir(d.path("/sys/block"))
+	if err != nil {
+		return topo, err
+	}
+
+	// Partición -> disco, para resolver miembros como sda1
+	parents := make(map[string]string)
+	for _, entry := range entries {
+		children, err := os.ReadDir(d.path("/sys/block/" + entry.Name()))
+		if err != nil {
+			continue
+		}
+		for _, c := range children {
+			if d.exists("/sys/block/" + entry.Name() + "/" + c.Name() + "/partition") {
+				parents[c.Name()] = entry.Name()
+			}
+		}
+	}
+
+	mdstat := parseMDStat(d.path("/proc/mdstat"))
+
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case strings.HasPrefix(name, "md") && d.exists("/sys/block/"+name+"/md"):
+			array := d.readRAIDArray(name, mdstat[name])
+			array.Disks = d.physicalDisks(name, parents)
+			topo.RAID = append(topo.RAID, array)
+		case strings.HasPrefix(name, "dm-"):
+			m := d.readDeviceMapping(name)
+			m.Disks = d.physicalDisks(name, parents)
+			topo.Mappings = append(topo.Mappings, m)
+		}
+	}
+
+	return topo, nil
+}
+
+// readRAIDArray lee un array md desde /sys/block/<name>/md
+func (d *Detector) readRAIDArray(name string, stat mdstatEntry) RAIDArray {
+	base := "/sys/block/" + name
+	array := RAIDArray{
+		Name:         name,
+		Status:       stat.status,
+		SyncProgress: -1,
+		Members:      make([]RAIDMember, 0),
+	}
+
+	array.Level, _ = d.readString(base + "/md/level")
+	array.State, _ = d.readString(base + "/md/array_state")
+	array.Metadata, _ = d.readString(base + "/md/metadata_version")
+	array.RaidDisks = d.readInt(base + "/md/raid_disks")
+	array.Degraded = d.readInt(base + "/md/degraded")
+	array.SizeBytes = uint64(d.readInt(base+"/size")) * 512
+
+	array.SyncAction, _ = d.readString(base + "/md/sync_action")
+	if array.SyncAction != "" && array.SyncAction != "idle" {
+		// sync_completed: "sectores hechos / total"
+		done, total, ok := strings.Cut(d.readFirst(base+"/md/sync_completed"), "/")
+		n, err1 := strconv.ParseFloat(strings.TrimSpace(done), 64)
+		t, err2 := strconv.ParseFloat(strings.TrimSpace(total), 64)
+		if ok && err1 == nil && err2 == nil && t > 0 {
+			array.SyncProgress = n / t * 100
+		}
+	}
+	if array.SyncProgress < 0 && stat.operation != "" {
+		array.SyncAction = stat.operation
+		array.SyncProgress = stat.progress
+	}
+	if array.SyncProgress >= 0 {
+		array.SyncFinish = stat.finish
+	}
+
+	devs, _ := os.ReadDir(d.path(base + "/md"))
+	for _, dev := range devs {
+		device, ok := strings.CutPrefix(dev.Name(), "dev-")
+		if !ok {
+			continue
+		}
+		member := RAIDMember{Device: device, Slot: -1}
+		member.State, _ = d.readString(base + "/md/" + dev.Name() + "/state")
+		if v, err := d.readString(base + "/md/" + dev.Name() + "/slot"); err == nil && v != "none" {
+			member.Slot, _ = strconv.Atoi(v)
+		}
+		array.Members = append(array.Members, member)
+	}
+	sort.Slice(array.Members, func(i, j int) bool {
+		a, b := array.Members[i], array.Members[j]
+		if a.Slot != b.Slot {
+			// Repuestos (-1) al final
+			return b.Slot < 0 || (a.Slot >= 0 && a.Slot < b.Slot)
+		}
+		return a.Device < b.Device
+	})
+
+	return array
+}
+
+// readDeviceMapping lee un dispositivo device-mapper desde /sys/block/dm-N
+func (d *Detector) readDeviceMapping(name string) DeviceMapping {
+	base := "/sys/block/" + name
+	m := DeviceMapping{
+		Name:      name,
+		SizeBytes: uint64(d.readInt(base+"/size")) * 512,
+		Slaves:    d.listDir(base + "/slaves"),
+		Holders:   d.listDir(base + "/holders"),
+	}
+	m.MapName, _ = d.readString(base + "/dm/name")
+	m.UUID, _ = d.readString(base + "/dm/uuid")
+	m.Kind = dmKind(m.UUID)
+	return m
+}
+
+// dmKind deduce el subsistema que creó un mapeo por el prefijo de su UUID
+// (LVM-, CRYPT-, mpath-, ...), que cada herramienta fija al crearlo
+func dmKind(uuid string) string {
+	prefix, _, _ := strings.Cut(uuid, "-")
+	switch strings.ToUpper(prefix) {
+	case "LVM":
+		return "lvm"
+	case "CRYPT":
+		return "crypt"
+	case "MPATH":
+		return "multipath"
+	case "DMRAID":
+		return "dmraid"
+	case "STRATIS":
+		return "stratis"
+	}
+	return "dm"
+}
+
+// physicalDisks sigue la cadena de slaves de un dispositivo de bloque hasta
+// los discos físicos (las particiones se sustituyen por su disco)
+func (d *Detector) physicalDisks(name string, parents map[string]string) []string {
+	disks := make([]string, 0)
+	seen := make(map[string]bool)
+
+	var walk func(dev string)
+	walk = func(dev string) {
+		if seen[dev] {
+			return
+		}
+		seen[dev] = true
+
+		if disk, ok := parents[dev]; ok {
+			dev = disk
+		}
+		slaves := d.listDir("/sys/block/" + dev + "/slaves")
+		if len(slaves) == 0 {
+			if dev != name {
+				disks = appendUnique(disks, dev)
+			}
+			return
+		}
+		for _, s := range slaves {
+			walk(s)
+		}
+	}
+	walk(name)
+
+	sort.Strings(disks)
+	return disks
+}
+
+// listDir devuelve los nombres de las entradas de un directorio de sysfs
+// (vacío si no existe)
+func (d *Detector) listDir(p string) []string {
+	names := make([]string, 0)
+	entries, err := os.ReadDir(d.path(p))
+	if err != nil {
+		return names
+	}
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+// parseMDStat interpreta /proc/mdstat: una línea "mdN : ..." por array
+// seguida de la línea con el estado de los miembros ([2/2] [UU]) y, si hay
+// una operación en curso, la de progreso
+func parseMDStat(path string) map[string]mdstatEntry {
+	arrays := make(map[string]mdstatEntry)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return arrays
+	}
+	defer f.Close()
+
+	current := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if name, _, ok := strings.Cut(line, " : "); ok && strings.HasPrefix(name, "md") {
+			current = strings.TrimSpace(name)
+			arrays[current] = mdstatEntry{}
+			continue
+		}
+		if current == "" || strings.TrimSpace(line) == "" {
+			current = ""
+			continue
+		}
+
+		entry := arrays[current]
+		if i := strings.LastIndex(line, "["); i >= 0 && entry.status == "" && strings.HasSuffix(strings.TrimSpace(line), "]") {
+			entry.status = strings.TrimSpace(line[i:])
+		}
+		if m := mdstatProgress.FindStringSubmatch(line); m != nil {
+			entry.operation = m[1]
+			entry.progress, _ = strconv.ParseFloat(m[2], 64)
+			entry.finish = m[3]
+		}
+		arrays[current] = entry
+	}
+	return arrays
+}
@@ -0,0 +1,342 @@
+package hardware
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Tipos de núcleo de CPUCore.Type en procesadores híbridos
+const (
+	CoreTypePerformance = "performance"
+	CoreTypeEfficiency  = "efficiency"
+)
+
+// cpuDirName reconoce los directorios de CPU lógicas (cpu0, cpu17...) frente
+// a cpufreq, cpuidle, etc.
+var cpuDirName = regexp.MustCompile(`^cpu(\d+)$`)
+
+// logicalCPU es lo que sysfs publica de una CPU lógica
+type logicalCPU struct {
+	id       int
+	pkg      int
+	die      int
+	core     int
+	node     int
+	capacity int
+	minMHz   float64
+	maxMHz   float64
+}
+
+// readCPUTopology completa cpu con la topología de /sys/devices/system/cpu y
+// /sys/devices/system/node: paquetes, dies, núcleos con sus hilos, tipo y
+// frecuencias, jerarquía de cachés y nodos NUMA. Devuelve false si sysfs no
+// expone la topología (ej: copia de /proc sin /sys).
+func (d *Detector) readCPUTopology(cpu *CPUInfo) bool {
+	base := "/sys/devices/system/cpu"
+	entries, err := os.ReadDir(d.path(base))
+	if err != nil {
+		return false
+	}
+
+	nodes := d.cpuNodes()
+	cpus := make([]logicalCPU, 0)
+	for _, entry := range entries {
+		m := cpuDirName.FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+		dir := base + "/" + entry.Name()
+		// Las CPU desconectadas no publican topology/
+		if !d.exists(dir + "/topology/core_id") {
+			continue
+		}
+		id, _ := strconv.Atoi(m[1])
+		c := logicalCPU{id: id}
+		c.pkg = d.readInt(dir + "/topology/physical_package_id")
+		c.die = d.readInt(dir + "/topology/die_id")
+		c.core = d.readInt(dir + "/topology/core_id")
+		c.capacity = d.readInt(dir + "/cpu_capacity")
+		c.minMHz = float64(d.readInt(dir+"/cpufreq/cpuinfo_min_freq")) / 1000
+		c.maxMHz = float64(d.readInt(dir+"/cpufreq/cpuinfo_max_freq")) / 1000
+		c.node = -1
+		if n, ok := nodes[c.id]; ok {
+			c.node = n
+		}
+		cpus = append(cpus, c)
+	}
+	if len(cpus) == 0 {
+		return false
+	}
+	sort.Slice(cpus, func(i, j int) bool { return cpus[i].id < cpus[j].id })
+
+	types := d.coreTypes(cpus)
+
+	// Un núcleo físico es único por (paquete, die, core_id): core_id se repite
+	// en cada socket
+	type coreKey struct{ pkg, die, core int }
+	index := make(map[coreKey]int)
+	packages := make(map[int]bool)
+	dies := make(map[[2]int]bool)
+	cpu.CoreList = make([]CPUCore, 0)
+	for _, c := range cpus {
+		packages[c.pkg] = true
+		dies[[2]int{c.pkg, c.die}] = true
+
+		key := coreKey{c.pkg, c.die, c.core}
+		n, ok := index[key]
+		if !ok {
+			n = len(cpu.CoreList)
+			index[key] = n
+			cpu.CoreList = append(cpu.CoreList, CPUCore{
+				Package: c.pkg,
+				Die:     c.die,
+				CoreID:  c.core,
+				Node:    c.node,
+				Type:    types[c.id],
+				MinMHz:  c.minMHz,
+				MaxMHz:  c.maxMHz,
+				CPUs:    make([]int, 0, 2),
+			})
+		}
+		core := &cpu.CoreList[n]
+		core.CPUs = append(core.CPUs, c.id)
+		core.MaxMHz = max(core.MaxMHz, c.maxMHz)
+	}
+
+	cpu.Packages = len(packages)
+	cpu.Dies = len(dies)
+	cpu.Cores = len(cpu.CoreList)
+	cpu.Threads = len(cpus)
+	for _, core := range cpu.CoreList {
+		if core.Type != "" {
+			cpu.Hybrid = true
+			break
+		}
+	}
+
+	cpu.Caches = d.readCPUCaches(cpus)
+	cpu.NUMANodes = d.readNUMANodes()
+	return true
+}
+
+// coreTypes clasifica las CPU de un procesador híbrido en núcleos de
+// rendimiento y de eficiencia. Intel publica las listas de cada tipo en las
+// PMU cpu_core y cpu_atom; en ARM (big.LITTLE) se usa cpu_capacity. Devuelve
+// un mapa vacío si todos los núcleos son iguales.
+func (d *Detector) coreTypes(cpus []logicalCPU) map[int]string {
+	types := make(map[int]string)
+
+	pcores, err1 := d.readString("/sys/devices/cpu_core/cpus")
+	ecores, err2 := d.readString("/sys/devices/cpu_atom/cpus")
+	if err1 == nil && err2 == nil {
+		for _, id := range parseCPUList(pcores) {
+			types[id] = CoreTypePerformance
+		}
+		for _, id := range parseCPUList(ecores) {
+			types[id] = CoreTypeEfficiency
+		}
+		return types
+	}
+
+	top := 0
+	for _, c := range cpus {
+		top = max(top, c.capacity)
+	}
+	for _, c := range cpus {
+		if c.capacity > 0 && c.capacity < top {
+			types[c.id] = CoreTypeEfficiency
+		}
+	}
+	if len(types) == 0 {
+		return types
+	}
+	for _, c := range cpus {
+		if c.capacity == top {
+			types[c.id] = CoreTypePerformance
+		}
+	}
+	return types
+}
+
+// readCPUCaches lee la jerarquía de cachés de cache/index* de cada CPU. Las
+// instancias compartidas (mismo shared_cpu_list) se cuentan una sola vez.
+func (d *Detector) readCPUCaches(cpus []logicalCPU) []CPUCache {
+	caches := make([]CPUCache, 0)
+	index := make(map[string]int)
+	seen := make(map[string]bool)
+
+	for _, c := range cpus {
+		base := fmt.Sprintf("/sys/devices/system/cpu/cpu%d/cache", c.id)
+		for _, dir := range d.listDir(base) {
+			if !strings.HasPrefix(dir, "index") {
+				continue
+			}
+			p := base + "/" + dir
+			level := d.readInt(p + "/level")
+			typ, _ := d.readString(p + "/type")
+			shared, _ := d.readString(p + "/shared_cpu_list")
+			if level == 0 {
+				continue
+			}
+
+			name := cacheName(level, typ)
+			if seen[name+" "+shared] {
+				continue
+			}
+			seen[name+" "+shared] = true
+
+			n, ok := index[name]
+			if !ok {
+				n = len(caches)
+				index[name] = n
+				size, _ := d.readString(p + "/size")
+				caches = append(caches, CPUCache{
+					Name:      name,
+					Level:     level,
+					Type:      typ,
+					SizeBytes: parseCacheSize(size),
+					Ways:      d.readInt(p + "/ways_of_associativity"),
+					LineSize:  d.readInt(p + "/coherency_line_size"),
+					SharedBy:  len(parseCPUList(shared)),
+				})
+			}
+			caches[n].Instances++
+			caches[n].TotalBytes += caches[n].SizeBytes
+		}
+	}
+
+	sort.Slice(caches, func(i, j int) bool { return caches[i].Name < caches[j].Name })
+	return caches
+}
+
+// cpuNodes relaciona cada CPU con su nodo NUMA
+func (d *Detector) cpuNodes() map[int]int {
+	nodes := make(map[int]int)
+	for _, dir := range d.listDir("/sys/devices/system/node") {
+		id, ok := strings.CutPrefix(dir, "node")
+		n, err := strconv.Atoi(id)
+		if !ok || err != nil {
+			continue
+		}
+		list, _ := d.readString("/sys/devices/system/node/" + dir + "/cpulist")
+		for _, c := range parseCPUList(list) {
+			nodes[c] = n
+		}
+	}
+	return nodes
+}
+
+// readNUMANodes lee los nodos NUMA con sus CPU y su memoria
+func (d *Detector) readNUMANodes() []NUMANode {
+	nodes := make([]NUMANode, 0)
+	for _, dir := range d.listDir("/sys/devices/system/node") {
+		id, ok := strings.CutPrefix(dir, "node")
+		n, err := strconv.Atoi(id)
+		if !ok || err != nil {
+			continue
+		}
+		base := "/sys/devices/system/node/" + dir
+		node := NUMANode{ID: n}
+		node.CPUs, _ = d.readString(base + "/cpulist")
+		node.MemTotalBytes, node.MemFreeBytes = d.nodeMemory(base + "/meminfo")
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes
+}
+
+// nodeMemory lee MemTotal y MemFree del meminfo de un nodo
+// ("Node 0 MemTotal:       32594284 kB")
+func (d *Detector) nodeMemory(p string) (total, free uint64) {
+	f, err := os.Open(d.path(p))
+	if err != nil {
+		return 0, 0
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[3], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[2] {
+		case "MemTotal:":
+			total = kb * 1024
+		case "MemFree:":
+			free = kb * 1024
+		}
+	}
+	return total, free
+}
+
+// cacheName devuelve el nombre habitual de una caché: L1d, L1i, L2, L3
+func cacheName(level int, typ string) string {
+	name := "L" + strconv.Itoa(level)
+	switch typ {
+	case "Data":
+		name += "d"
+	case "Instruction":
+		name += "i"
+	}
+	return name
+}
+
+// parseCacheSize interpreta el tamaño de caché de sysfs ("48K", "30720K",
+// "2M") en bytes
+func parseCacheSize(s string) uint64 {
+	mult := uint64(1)
+	switch {
+	case strings.HasSuffix(s, "K"):
+		mult, s = 1<<10, strings.TrimSuffix(s, "K")
+	case strings.HasSuffix(s, "M"):
+		mult, s = 1<<20, strings.TrimSuffix(s, "M")
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n * mult
+}
+
+// formatCacheSize formatea el tamaño de una caché ("48 KB", "1.25 MB")
+func formatCacheSize(b uint64) string {
+	if b >= 1<<20 {
+		return strconv.FormatFloat(float64(b)/(1<<20), 'f', -1, 64) + " MB"
+	}
+	return fmt.Sprintf("%d KB", b/1024)
+}
+
+// parseCPUList expande una lista de CPU de sysfs ("0-3,8,10-11")
+func parseCPUList(s string) []int {
+	cpus := make([]int, 0)
+	for _, part := range strings.Split(strings.TrimSpace(s), ",") {
+		if part == "" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(part, "-")
+		a, err := strconv.Atoi(lo)
+		if err != nil {
+			continue
+		}
+		b := a
+		if isRange {
+			if b, err = strconv.Atoi(hi); err != nil {
+				continue
+			}
+		}
+		for i := a; i <= b; i++ {
+			cpus = append(cpus, i)
+		}
+	}
+	return cpus
+}
@@ -0,0 +1,503 @@
+package hardware
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Versiones de TPMInfo.Version
+const (
+	TPMVersion12 = "1.2"
+	TPMVersion20 = "2.0"
+)
+
+// Comandos, capacidades y propiedades de TPM 2.0 (TCG TPM 2.0 Library,
+// parte 2)
+const (
+	tpmSTNoSessions     = 0x8001
+	tpmCCGetCapability  = 0x0000017A
+	tpmCCPCRRead        = 0x0000017E
+	tpmCapPCRs          = 0x00000005
+	tpmCapTPMProperties = 0x00000006
+	tpmPTFixed          = 0x100 // Primera propiedad fija (TPM_PT_FAMILY_INDICATOR)
+	tpmPTVar            = 0x200 // Primera propiedad variable (TPM_PT_PERMANENT)
+	tpmPTManufacturer   = 0x105
+	tpmPTVendorString1  = 0x106
+	tpmPTFirmware1      = 0x10B
+	tpmPTFirmware2      = 0x10C
+	tpmPTPermanent      = 0x200
+	tpmPTStartupClear   = 0x201
+	tpmAlgSHA256        = 0x000B
+	tpmPCRCount         = 24
+	tpmHeaderSize       = 10
+	tpmMaxResponse      = 4096
+)
+
+// Bits de TPMA_PERMANENT (ownerAuthSet) y TPMA_STARTUP_CLEAR (jerarquías de
+// almacenamiento y de endoso habilitadas)
+const (
+	tpmaOwnerAuthSet = 1 << 0
+	tpmaSHEnable     = 1 << 1
+	tpmaEHEnable     = 1 << 2
+)
+
+var errTPMTruncated = errors.New("respuesta TPM truncada")
+
+// tpmAlgorithms nombra los algoritmos hash de los bancos de PCR con los
+// mismos nombres que los directorios pcr-<alg> de sysfs
+var tpmAlgorithms = map[uint16]string{
+	0x0004: "sha1",
+	0x000B: "sha256",
+	0x000C: "sha384",
+	0x000D: "sha512",
+	0x0012: "sm3_256",
+}
+
+// tpmManufacturers relaciona el código TCG del fabricante (TPM_PT_MANUFACTURER
+// en TPM 2.0, "Manufacturer" en TPM 1.2) con su nombre
+var tpmManufacturers = map[string]string{
+	"AMD":  "AMD",
+	"ATML": "Atmel",
+	"BRCM": "Broadcom",
+	"CSCO": "Cisco",
+	"FLYS": "Flyslice",
+	"GOOG": "Google",
+	"HISI": "HiSilicon",
+	"HPE":  "HPE",
+	"IBM":  "IBM",
+	"IFX":  "Infineon",
+	"INTC": "Intel",
+	"LEN":  "Lenovo",
+	"MSFT": "Microsoft",
+	"NSM":  "National Semiconductor",
+	"NTC":  "Nuvoton",
+	"NTZ":  "Nationz",
+	"QCOM": "Qualcomm",
+	"ROCC": "Rockchip",
+	"SMSC": "SMSC",
+	"SMSN": "Samsung",
+	"SNS":  "Sinosun",
+	"STM":  "STMicroelectronics",
+	"TXN":  "Texas Instruments",
+	"WEC":  "Winbond",
+}
+
+// detectTPM describe el TPM del equipo a partir de /sys/class/tpm: versión,
+// estado (TPM 1.2) y bancos de PCR con los valores SHA-256 (TPM 2.0, kernel
+// 5.12+). En TPM 2.0 el fabricante, el firmware y el estado se consultan con
+// comandos TPM2_GetCapability sobre /dev/tpmrm0, que también completan los
+// bancos y los PCR si el kernel no los publica.
+func (d *Detector) detectTPM(rep *report) (TPMInfo, error) {
+	tpm := TPMInfo{
+		Banks: make([]string, 0),
+		PCRs:  make([]TPMPCR, 0),
+	}
+
+	if !d.exists("/sys/class") {
+		rep.skip("sin /sys/class: no se puede detectar el TPM")
+		return tpm, nil
+	}
+	for _, name := range d.listDir("/sys/class/tpm") {
+		if strings.HasPrefix(name, "tpm") {
+			tpm.Device = name
+			break
+		}
+	}
+	if tpm.Device == "" {
+		rep.skip("sin TPM (o sin su driver: tpm_tis, tpm_crb)")
+		return tpm, nil
+	}
+	tpm.Present = true
+
+	base := "/sys/class/tpm/" + tpm.Device
+	tpm.Version = d.tpmVersion(base)
+	if tpm.Version == TPMVersion12 {
+		d.readTPM12(base, &tpm)
+		return tpm, nil
+	}
+
+	for _, dir := range d.listDir(base) {
+		if alg, ok := strings.CutPrefix(dir, "pcr-"); ok {
+			tpm.Banks = append(tpm.Banks, alg)
+		}
+	}
+	tpm.PCRs = d.sysfsPCRs(base + "/pcr-sha256")
+
+	rw, err := d.openTPM()
+	switch {
+	case os.IsPermission(err):
+		rep.note("sin permiso para abrir /dev/tpmrm0 (requiere root o el grupo tss): sin fabricante ni estado")
+		return tpm, nil
+	case err != nil:
+		rep.note("/dev/tpmrm0: %v", err)
+		return tpm, nil
+	case rw == nil:
+		rep.note("fabricante y estado del TPM solo se consultan en el sistema en ejecución")
+		return tpm, nil
+	}
+	defer rw.Close()
+
+	if err := readTPM2(rw, &tpm); err != nil {
+		rep.degrade("comandos TPM 2.0: %v", err)
+	}
+	return tpm, nil
+}
+
+// tpmVersion devuelve la versión de la especificación del TPM. Los kernels
+// anteriores a 5.6 no publican tpm_version_major; en ellos solo TPM 1.2
+// tiene el archivo caps.
+func (d *Detector) tpmVersion(base string) string {
+	switch v, _ := d.readString(base + "/tpm_version_major"); v {
+	case "1":
+		return TPMVersion12
+	case "2":
+		return TPMVersion20
+	}
+	if d.exists(base+"/caps") || d.exists(base+"/device/caps") {
+		return TPMVersion12
+	}
+	return TPMVersion20
+}
+
+// readTPM12 lee el fabricante, el firmware y el estado de un TPM 1.2. Los
+// kernels anteriores a 4.x los publican en device/ en lugar de tpm0/.
+func (d *Detector) readTPM12(base string, tpm *TPMInfo) {
+	dir := base
+	if !d.exists(dir + "/caps") {
+		dir = base + "/device"
+	}
+
+	// "Manufacturer: 0x49465800", "TCG version: 1.2", "Firmware version: 3.19"
+	if caps, err := d.readString(dir + "/caps"); err == nil {
+		for _, line := range strings.Split(caps, "\n") {
+			key, value, ok := strings.Cut(line, ":")
+			if !ok {
+				continue
+			}
+			value = strings.TrimSpace(value)
+			switch strings.TrimSpace(key) {
+			case "Manufacturer":
+				if n, err := strconv.ParseUint(strings.TrimPrefix(value, "0x"), 16, 32); err == nil {
+					setTPMManufacturer(tpm, uint32(n))
+				}
+			case "Firmware version":
+				tpm.FirmwareVersion = value
+			}
+		}
+	}
+
+	// Un TPM 1.2 solo es utilizable habilitado y activado
+	if v, err := d.readString(dir + "/enabled"); err == nil {
+		enabled := v == "1"
+		if a, err := d.readString(dir + "/active"); err == nil {
+			enabled = enabled && a == "1"
+		}
+		tpm.Enabled = &enabled
+	}
+	if v, err := d.readString(dir + "/owned"); err == nil {
+		owned := v == "1"
+		tpm.Owned = &owned
+	}
+	tpm.Banks = append(tpm.Banks, "sha1")
+}
+
+// sysfsPCRs lee los valores de un banco de PCR publicados por el kernel
+// (pcr-sha256/0 ... pcr-sha256/23)
+func (d *Detector) sysfsPCRs(dir string) []TPMPCR {
+	pcrs := make([]TPMPCR, 0)
+	for i := 0; i < tpmPCRCount; i++ {
+		v, err := d.readString(dir + "/" + strconv.Itoa(i))
+		if err != nil {
+			continue
+		}
+		pcrs = append(pcrs, TPMPCR{Index: i, Value: strings.ToUpper(v)})
+	}
+	return pcrs
+}
+
+// openTPM abre el dispositivo TPM 2.0: el de Detector.OpenTPM si se
+// configuró y, si no, /dev/tpmrm0 (o /dev/tpm0) del sistema en ejecución.
+// Devuelve nil sin error si no hay dispositivo que consultar.
+func (d *Detector) openTPM() (io.ReadWriteCloser, error) {
+	if d.OpenTPM != nil {
+		return d.OpenTPM()
+	}
+	if !d.live() {
+		return nil, nil
+	}
+	// tpmrm0 pasa por el gestor de recursos del kernel; tpm0 es de uso
+	// exclusivo y puede estar ocupado por tpm2-abrmd
+	f, err := os.OpenFile("/dev/tpmrm0", os.O_RDWR, 0)
+	if os.IsNotExist(err) {
+		f, err = os.OpenFile("/dev/tpm0", os.O_RDWR, 0)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// readTPM2 completa tpm con comandos TPM 2.0 enviados por rw: fabricante,
+// firmware y estado (TPM2_GetCapability) y, si sysfs no los publica, los
+// bancos de PCR asignados y los valores del banco SHA-256 (TPM2_PCR_Read)
+func readTPM2(rw io.ReadWriter, tpm *TPMInfo) error {
+	fixed, err := tpm2Properties(rw, tpmPTFixed)
+	if err != nil {
+		return err
+	}
+	if v, ok := fixed[tpmPTManufacturer]; ok {
+		setTPMManufacturer(tpm, v)
+	}
+	// Texto libre del fabricante en 4 propiedades de 4 caracteres
+	vendor := make([]byte, 0, 16)
+	for p := uint32(tpmPTVendorString1); p < tpmPTVendorString1+4; p++ {
+		vendor = binary.BigEndian.AppendUint32(vendor, fixed[p])
+	}
+	tpm.VendorString = strings.TrimSpace(strings.ReplaceAll(string(vendor), "\x00", ""))
+	if v1, ok := fixed[tpmPTFirmware1]; ok {
+		v2 := fixed[tpmPTFirmware2]
+		tpm.FirmwareVersion = fmt.Sprintf("%d.%d.%d.%d", v1>>16, v1&0xFFFF, v2>>16, v2&0xFFFF)
+	}
+
+	variable, err := tpm2Properties(rw, tpmPTVar)
+	if err != nil {
+		return err
+	}
+	if v, ok := variable[tpmPTPermanent]; ok {
+		owned := v&tpmaOwnerAuthSet != 0
+		tpm.Owned = &owned
+	}
+	if v, ok := variable[tpmPTStartupClear]; ok {
+		enabled := v&(tpmaSHEnable|tpmaEHEnable) == tpmaSHEnable|tpmaEHEnable
+		tpm.Enabled = &enabled
+	}
+
+	if len(tpm.Banks) == 0 {
+		if tpm.Banks, err = tpm2PCRBanks(rw); err != nil {
+			return err
+		}
+	}
+	if len(tpm.PCRs) == 0 {
+		for _, bank := range tpm.Banks {
+			if bank == tpmAlgorithms[tpmAlgSHA256] {
+				tpm.PCRs, err = tpm2ReadPCRs(rw, tpmAlgSHA256)
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// tpm2Command arma un comando TPM 2.0 sin sesiones: cabecera (tag, tamaño
+// total y código de comando) seguida de los parámetros, en big endian
+func tpm2Command(code uint32, params []byte) []byte {
+	cmd := make([]byte, tpmHeaderSize, tpmHeaderSize+len(params))
+	binary.BigEndian.PutUint16(cmd, tpmSTNoSessions)
+	binary.BigEndian.PutUint32(cmd[2:], uint32(tpmHeaderSize+len(params)))
+	binary.BigEndian.PutUint32(cmd[6:], code)
+	return append(cmd, params...)
+}
+
+// tpm2Transmit envía un comando y devuelve los parámetros de la respuesta,
+// sin la cabecera. El dispositivo entrega la respuesta completa en una sola
+// lectura; un código de respuesta distinto de cero es un error.
+func tpm2Transmit(rw io.ReadWriter, cmd []byte) ([]byte, error) {
+	if _, err := rw.Write(cmd); err != nil {
+		return nil, err
+	}
+	resp := make([]byte, tpmMaxResponse)
+	n, err := rw.Read(resp)
+	if err != nil {
+		return nil, err
+	}
+	resp = resp[:n]
+	if n < tpmHeaderSize {
+		return nil, errTPMTruncated
+	}
+	if size := binary.BigEndian.Uint32(resp[2:]); int(size) != n {
+		return nil, fmt.Errorf("respuesta TPM de %d bytes, la cabecera indica %d", n, size)
+	}
+	if rc := binary.BigEndian.Uint32(resp[6:]); rc != 0 {
+		return nil, fmt.Errorf("código de respuesta TPM 0x%03X", rc)
+	}
+	return resp[tpmHeaderSize:], nil
+}
+
+// tpm2Properties lee con TPM2_GetCapability(TPM_CAP_TPM_PROPERTIES) las
+// propiedades del grupo que empieza en first (fijas 0x1xx o variables
+// 0x2xx), repitiendo la consulta mientras el TPM indique que hay más
+func tpm2Properties(rw io.ReadWriter, first uint32) (map[uint32]uint32, error) {
+	props := make(map[uint32]uint32)
+	next := first
+	for {
+		params := make([]byte, 12)
+		binary.BigEndian.PutUint32(params, tpmCapTPMProperties)
+		binary.BigEndian.PutUint32(params[4:], next)
+		binary.BigEndian.PutUint32(params[8:], 0xFF)
+		resp, err := tpm2Transmit(rw, tpm2Command(tpmCCGetCapability, params))
+		if err != nil {
+			return props, err
+		}
+
+		// moreData (1), capability (4), count (4) y pares (propiedad, valor)
+		if len(resp) < 9 {
+			return props, errTPMTruncated
+		}
+		more := resp[0] != 0
+		count := int(binary.BigEndian.Uint32(resp[5:]))
+		list := resp[9:]
+		if count*8 > len(list) {
+			return props, errTPMTruncated
+		}
+		for i := 0; i < count; i++ {
+			p := binary.BigEndian.Uint32(list[i*8:])
+			if p&^0xFF != first {
+				return props, nil
+			}
+			props[p] = binary.BigEndian.Uint32(list[i*8+4:])
+			next = p + 1
+		}
+		if !more || count == 0 {
+			return props, nil
+		}
+	}
+}
+
+// tpm2PCRBanks devuelve los bancos de PCR asignados según
+// TPM2_GetCapability(TPM_CAP_PCRS): los algoritmos con algún PCR
+// seleccionado
+func tpm2PCRBanks(rw io.ReadWriter) ([]string, error) {
+	params := make([]byte, 12)
+	binary.BigEndian.PutUint32(params, tpmCapPCRs)
+	binary.BigEndian.PutUint32(params[8:], 1)
+	resp, err := tpm2Transmit(rw, tpm2Command(tpmCCGetCapability, params))
+	if err != nil {
+		return make([]string, 0), err
+	}
+
+	// moreData (1), capability (4) y TPML_PCR_SELECTION
+	if len(resp) < 5 {
+		return make([]string, 0), errTPMTruncated
+	}
+	banks := make([]string, 0)
+	_, err = parsePCRSelection(resp[5:], func(alg uint16, mask uint32) {
+		if mask == 0 {
+			return
+		}
+		name, ok := tpmAlgorithms[alg]
+		if !ok {
+			name = fmt.Sprintf("0x%04X", alg)
+		}
+		banks = append(banks, name)
+	})
+	return banks, err
+}
+
+// tpm2ReadPCRs lee los PCR 0-23 de un banco con TPM2_PCR_Read. El TPM
+// devuelve como mucho 8 digests por llamada e indica en la selección de
+// salida cuáles leyó; se repite con los que faltan.
+func tpm2ReadPCRs(rw io.ReadWriter, alg uint16) ([]TPMPCR, error) {
+	pcrs := make([]TPMPCR, 0, tpmPCRCount)
+	pending := uint32(1)<<tpmPCRCount - 1
+	for pending != 0 {
+		// TPML_PCR_SELECTION con un solo banco y 3 bytes de máscara
+		params := []byte{0, 0, 0, 1, byte(alg >> 8), byte(alg), 3, byte(pending), byte(pending >> 8), byte(pending >> 16)}
+		resp, err := tpm2Transmit(rw, tpm2Command(tpmCCPCRRead, params))
+		if err != nil {
+			return pcrs, err
+		}
+
+		// pcrUpdateCounter (4), TPML_PCR_SELECTION y TPML_DIGEST
+		if len(resp) < 4 {
+			return pcrs, errTPMTruncated
+		}
+		var read uint32
+		rest, err := parsePCRSelection(resp[4:], func(a uint16, mask uint32) {
+			if a == alg {
+				read |= mask
+			}
+		})
+		if err != nil {
+			return pcrs, err
+		}
+		read &= pending
+		if read == 0 {
+			break
+		}
+		if len(rest) < 4 {
+			return pcrs, errTPMTruncated
+		}
+		if n := binary.BigEndian.Uint32(rest); int(n) != bits.OnesCount32(read) {
+			return pcrs, fmt.Errorf("TPM2_PCR_Read devolvió %d digests para %d PCR", n, bits.OnesCount32(read))
+		}
+		rest = rest[4:]
+		for i := 0; i < tpmPCRCount; i++ {
+			if read&(1<<i) == 0 {
+				continue
+			}
+			if len(rest) < 2 {
+				return pcrs, errTPMTruncated
+			}
+			size := int(binary.BigEndian.Uint16(rest))
+			if len(rest) < 2+size {
+				return pcrs, errTPMTruncated
+			}
+			pcrs = append(pcrs, TPMPCR{Index: i, Value: strings.ToUpper(hex.EncodeToString(rest[2 : 2+size]))})
+			rest = rest[2+size:]
+		}
+		pending &^= read
+	}
+	return pcrs, nil
+}
+
+// parsePCRSelection recorre una TPML_PCR_SELECTION (count y, por banco,
+// algoritmo, tamaño de la máscara y máscara en little endian) llamando a fn
+// con cada banco. Devuelve lo que sigue a la lista.
+func parsePCRSelection(b []byte, fn func(alg uint16, mask uint32)) ([]byte, error) {
+	if len(b) < 4 {
+		return nil, errTPMTruncated
+	}
+	count := int(binary.BigEndian.Uint32(b))
+	b = b[4:]
+	for i := 0; i < count; i++ {
+		if len(b) < 3 {
+			return nil, errTPMTruncated
+		}
+		alg := binary.BigEndian.Uint16(b)
+		size := int(b[2])
+		if len(b) < 3+size {
+			return nil, errTPMTruncated
+		}
+		var mask uint32
+		for j := 0; j < size && j < 4; j++ {
+			mask |= uint32(b[3+j]) << (8 * j)
+		}
+		fn(alg, mask)
+		b = b[3+size:]
+	}
+	return b, nil
+}
+
+// setTPMManufacturer asigna el código TCG del fabricante (4 caracteres ASCII
+// en un entero big endian) y su nombre
+func setTPMManufacturer(tpm *TPMInfo, v uint32) {
+	tpm.ManufacturerID = fourCC(v)
+	tpm.Manufacturer = tpm.ManufacturerID
+	if name, ok := tpmManufacturers[tpm.ManufacturerID]; ok {
+		tpm.Manufacturer = name
+	}
+}
+
+// fourCC convierte un entero big endian en sus 4 caracteres ASCII, sin NUL
+// ni espacios al final ("IFX\0" -> "IFX")
+func fourCC(v uint32) string {
+	b := []byte{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)}
+	return strings.TrimRight(string(b), "\x00 ")
+}
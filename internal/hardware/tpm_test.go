@@ -0,0 +1,208 @@
+package hardware
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// replayTPM simula /dev/tpmrm0 reproduciendo una sesión grabada: cada Write
+// debe coincidir con el siguiente comando y el Read siguiente devuelve su
+// respuesta completa, como el dispositivo real
+type replayTPM struct {
+	t        *testing.T
+	commands [][]byte
+	replies  [][]byte
+	next     int
+	reply    []byte
+	closed   bool
+}
+
+// loadReplay lee una sesión de testdata/tpm: líneas "> comando" y
+// "< respuesta" en hexadecimal; "#" inicia un comentario
+func loadReplay(t *testing.T, name string) *replayTPM {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", "tpm", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	tpm := &replayTPM{t: t}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), tpmMaxResponse*2+16)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		b, err := hex.DecodeString(strings.TrimSpace(line[1:]))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		switch line[0] {
+		case '>':
+			tpm.commands = append(tpm.commands, b)
+		case '<':
+			tpm.replies = append(tpm.replies, b)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(tpm.commands) != len(tpm.replies) {
+		t.Fatalf("%s: %d comandos y %d respuestas", name, len(tpm.commands), len(tpm.replies))
+	}
+	return tpm
+}
+
+func (r *replayTPM) Write(b []byte) (int, error) {
+	if r.next >= len(r.commands) {
+		r.t.Errorf("comando de más: %x", b)
+		return 0, errors.New("sesión agotada")
+	}
+	if !bytes.Equal(b, r.commands[r.next]) {
+		r.t.Errorf("comando %d = %x, se esperaba %x", r.next, b, r.commands[r.next])
+	}
+	r.reply = r.replies[r.next]
+	r.next++
+	return len(b), nil
+}
+
+func (r *replayTPM) Read(b []byte) (int, error) {
+	if r.reply == nil {
+		return 0, io.EOF
+	}
+	n := copy(b, r.reply)
+	r.reply = nil
+	return n, nil
+}
+
+func (r *replayTPM) Close() error {
+	r.closed = true
+	return nil
+}
+
+// expectedPCR es el valor del PCR i en la sesión slb9670.replay: digests de
+// arranque en 0-9, sin extender en 10-16 y 23 y de localidad en 17-22
+func expectedPCR(i int) string {
+	switch {
+	case i >= 17 && i <= 22:
+		return strings.Repeat("FF", 32)
+	case i >= 10:
+		return strings.Repeat("00", 32)
+	}
+	sum := sha256.Sum256([]byte("pcr" + strconv.Itoa(i)))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func TestDetectTPM2(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "sys", "class", "tpm", "tpm0")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tpm_version_major"), []byte("2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Sin pcr-sha256 en sysfs: bancos y PCR salen de los comandos
+	dev := loadReplay(t, "slb9670.replay")
+	d := NewDetector(root)
+	d.OpenTPM = func() (io.ReadWriteCloser, error) { return dev, nil }
+
+	rep := &report{}
+	tpm, err := d.detectTPM(rep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.partial || rep.skipped {
+		t.Errorf("detector marcado como parcial u omitido: %v", rep.messages)
+	}
+	if dev.next != len(dev.commands) {
+		t.Errorf("se enviaron %d de %d comandos", dev.next, len(dev.commands))
+	}
+	if !dev.closed {
+		t.Error("el dispositivo no se cerró")
+	}
+
+	checks := []struct{ field, got, want string }{
+		{"Device", tpm.Device, "tpm0"},
+		{"Version", tpm.Version, TPMVersion20},
+		{"ManufacturerID", tpm.ManufacturerID, "IFX"},
+		{"Manufacturer", tpm.Manufacturer, "Infineon"},
+		{"VendorString", tpm.VendorString, "SLB9670"},
+		{"FirmwareVersion", tpm.FirmwareVersion, "7.85.4555.0"},
+		{"Banks", strings.Join(tpm.Banks, ","), "sha1,sha256"},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %q, se esperaba %q", c.field, c.got, c.want)
+		}
+	}
+	if !tpm.Present {
+		t.Error("Present = false")
+	}
+	if tpm.Owned == nil || !*tpm.Owned {
+		t.Error("Owned no es true")
+	}
+	if tpm.Enabled == nil || !*tpm.Enabled {
+		t.Error("Enabled no es true")
+	}
+
+	// Tres rondas de TPM2_PCR_Read con 8 digests cada una
+	if len(tpm.PCRs) != tpmPCRCount {
+		t.Fatalf("%d PCR, se esperaban %d", len(tpm.PCRs), tpmPCRCount)
+	}
+	for i, pcr := range tpm.PCRs {
+		if pcr.Index != i {
+			t.Errorf("PCR en posición %d con índice %d", i, pcr.Index)
+		}
+		if want := expectedPCR(i); pcr.Value != want {
+			t.Errorf("PCR %d = %s, se esperaba %s", i, pcr.Value, want)
+		}
+	}
+}
+
+// fixedTPM responde siempre lo mismo a cualquier comando
+type fixedTPM []byte
+
+func (f fixedTPM) Write(b []byte) (int, error) { return len(b), nil }
+func (f fixedTPM) Read(b []byte) (int, error)  { return copy(b, f), nil }
+
+func TestTPM2TransmitErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		resp string
+	}{
+		{"cabecera truncada", "80010000"},
+		{"tamaño distinto de la cabecera", "80010000001000000000"},
+		{"TPM_RC_FAILURE", "80010000000a00000101"},
+		{"TPM_RC_INITIALIZE", "80c40000000a00000100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, _ := hex.DecodeString(tt.resp)
+			if _, err := tpm2Transmit(fixedTPM(resp), tpm2Command(tpmCCGetCapability, nil)); err == nil {
+				t.Error("respuesta aceptada")
+			}
+		})
+	}
+}
+
+func TestReadTPM2Truncated(t *testing.T) {
+	// GetCapability que anuncia 16 propiedades y solo trae una
+	resp, _ := hex.DecodeString("80010000001b00000000" + "01" + "00000006" + "00000010" + "00000100322e3000")
+	var tpm TPMInfo
+	if err := readTPM2(fixedTPM(resp), &tpm); !errors.Is(err, errTPMTruncated) {
+		t.Errorf("error = %v, se esperaba %v", err, errTPMTruncated)
+	}
+}
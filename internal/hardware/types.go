@@ -2,13 +2,56 @@ package hardware
 
 // HardwareInfo contiene toda la información del hardware detectado
 type HardwareInfo struct {
-	MachineID   string          `json:"machine_id"` // Identificador único de la máquina
-	CPU         CPUInfo         `json:"cpu"`
-	Memory      MemoryInfo      `json:"memory"`
-	Motherboard MotherboardInfo `json:"motherboard"`
-	GPU         []GPUInfo       `json:"gpu"`
-	Disks       []DiskInfo      `json:"disks"`
-	Timestamp   string          `json:"timestamp"`
+	MachineID    string             `json:"machine_id"` // Identificador único de la máquina
+	Platform     PlatformInfo       `json:"platform"`   // Máquina virtual o contenedor
+	CPU          CPUInfo            `json:"cpu"`
+	Memory       MemoryInfo         `json:"memory"`
+	Motherboard  MotherboardInfo    `json:"motherboard"`
+	System       SystemInfo         `json:"system"`
+	Firmware     FirmwareInfo       `json:"firmware"` // Modo de arranque, Secure Boot y entradas de arranque
+	TPM          TPMInfo            `json:"tpm"`      // Trusted Platform Module
+	GPU          []GPUInfo          `json:"gpu"`
+	Displays     []DisplayInfo      `json:"displays"` // Monitores y paneles conectados, con su EDID
+	PCI          []PCIDevice        `json:"pci"`
+	Disks        []DiskInfo         `json:"disks"`
+	Storage      StorageTopology    `json:"storage"` // Arrays md y mapeos device-mapper
+	Network      []NetworkInterface `json:"network"`
+	USB          []USBDevice        `json:"usb"`           // Árbol de buses USB (un hub raíz por bus)
+	Power        PowerInfo          `json:"power"`         // Baterías y adaptadores de corriente
+	Sensors      []SensorChip       `json:"sensors"`       // Lectura de los sensores hwmon, por chip
+	SensorSeries []SensorTrend      `json:"sensor_series"` // Serie muestreada de los sensores (vacía si no se pidió)
+	Timestamp    string             `json:"timestamp"`
+	TimedOut     []string           `json:"timed_out"` // Detectores que excedieron su tiempo límite
+
+	Sections    map[string]Section   `json:"sections"`    // Secciones de plugins registrados, por nombre
+	Diagnostics []DetectorDiagnostic `json:"diagnostics"` // Estado de cada detector
+}
+
+// PlatformInfo indica si el reporte describe hardware virtual: una máquina
+// virtual (Hypervisor) o un contenedor (Container), cuyo hardware es el del
+// anfitrión
+type PlatformInfo struct {
+	Virtual    bool     `json:"virtual"`    // No es un equipo físico propio: VM o contenedor
+	Hypervisor string   `json:"hypervisor"` // KVM, QEMU, VMware, Hyper-V, Xen, VirtualBox, unknown... (vacío en hardware real)
+	Container  string   `json:"container"`  // docker, podman, lxc, systemd-nspawn, kubernetes, wsl
+	Evidence   []string `json:"evidence"`   // Indicios encontrados (DMI, CPUID, /sys/hypervisor, runtime)
+}
+
+// Section es el resultado de un plugin registrado con Register
+type Section struct {
+	Title string `json:"title"` // Título para consola y web
+	Data  any    `json:"data"`  // Datos serializables a JSON devueltos por el plugin
+}
+
+// DetectorDiagnostic describe cómo terminó un detector: permite distinguir
+// "no hay GPU" de "no se pudo consultar el bus PCI"
+type DetectorDiagnostic struct {
+	Name       string   `json:"name"`        // Nombre del detector (cpu, memory, gpu...)
+	Status     string   `json:"status"`      // ok, partial, failed o skipped
+	Source     string   `json:"source"`      // Fuente usada (procfs, sysfs, smbios, dmidecode, fallback)
+	DurationMS int64    `json:"duration_ms"` // Duración de la detección
+	TimedOut   bool     `json:"timed_out"`   // Excedió su tiempo límite
+	Messages   []string `json:"messages"`    // Errores y advertencias no fatales
 }
 
 // CPUInfo contiene información del procesador
@@ -20,6 +63,84 @@ type CPUInfo struct {
 	Speed     float64  `json:"speed_mhz"`  // Velocidad en MHz
 	CacheSize string   `json:"cache_size"` // Tamaño de caché
 	Flags     []string `json:"flags"`      // Características del CPU
+
+	// Identificación y postura de seguridad
+	Family          int                `json:"family"`          // cpu family
+	ModelNumber     int                `json:"model_number"`    // model (número, no el nombre)
+	Stepping        int                `json:"stepping"`        // Revisión del silicio
+	Microcode       string             `json:"microcode"`       // Revisión de microcódigo cargada (ej: 0xf4)
+	OldMicrocode    bool               `json:"old_microcode"`   // El kernel considera el microcódigo desactualizado
+	Bugs            []string           `json:"bugs"`            // Erratas conocidas por el kernel (línea bugs)
+	Vulnerabilities []CPUVulnerability `json:"vulnerabilities"` // Estado de cada vulnerabilidad
+	CPUID           *CPUIDInfo         `json:"cpuid"`           // CPUID nativo (solo amd64 y escaneando el sistema en ejecución)
+
+	// Topología desde /sys/devices/system/cpu y /sys/devices/system/node
+	Packages  int        `json:"packages"`   // Sockets ocupados
+	Dies      int        `json:"dies"`       // Dies en total (chiplets)
+	Hybrid    bool       `json:"hybrid"`     // Núcleos de rendimiento y de eficiencia
+	CoreList  []CPUCore  `json:"core_list"`  // Núcleos físicos con sus hilos
+	Caches    []CPUCache `json:"caches"`     // Jerarquía de cachés (L1d, L1i, L2, L3)
+	NUMANodes []NUMANode `json:"numa_nodes"` // Nodos NUMA
+}
+
+// CPUIDInfo es lo que informa la instrucción CPUID ejecutada directamente,
+// contrastado con /proc/cpuinfo
+type CPUIDInfo struct {
+	Vendor           string     `json:"vendor"`            // GenuineIntel, AuthenticAMD...
+	Brand            string     `json:"brand"`             // Cadena de marca (hojas 0x80000002-4)
+	Family           int        `json:"family"`            // Familia (con familia extendida)
+	Model            int        `json:"model"`             // Modelo (con modelo extendido)
+	Stepping         int        `json:"stepping"`          // Stepping
+	MaxLeaf          uint32     `json:"max_leaf"`          // Hoja básica más alta
+	MaxExtLeaf       uint32     `json:"max_ext_leaf"`      // Hoja extendida más alta
+	Features         []string   `json:"features"`          // Características, con los nombres de /proc/cpuinfo
+	Caches           []CPUCache `json:"caches"`            // Parámetros de caché (hoja 4 o 0x8000001D)
+	Hypervisor       string     `json:"hypervisor"`        // KVM, VMware, Hyper-V, unknown... (vacío en hardware real)
+	HypervisorVendor string     `json:"hypervisor_vendor"` // Firma de la hoja 0x40000000
+	HiddenFeatures   []string   `json:"hidden_features"`   // En CPUID pero no en /proc/cpuinfo (desactivadas por el kernel)
+	Mismatches       []string   `json:"mismatches"`        // Diferencias con /proc/cpuinfo
+}
+
+// CPUVulnerability es el estado de una vulnerabilidad de CPU según
+// /sys/devices/system/cpu/vulnerabilities
+type CPUVulnerability struct {
+	Name   string `json:"name"`   // spectre_v2, mds, retbleed...
+	Status string `json:"status"` // Not affected, Mitigated, Vulnerable o Unknown
+	Detail string `json:"detail"` // Mitigación aplicada o motivo
+}
+
+// CPUCore es un núcleo físico, identificado por paquete, die y core_id
+type CPUCore struct {
+	Package int     `json:"package"` // physical_package_id
+	Die     int     `json:"die"`     // die_id dentro del paquete
+	CoreID  int     `json:"core_id"` // core_id (se repite en cada paquete)
+	Node    int     `json:"node"`    // Nodo NUMA (-1 si no hay NUMA)
+	Type    string  `json:"type"`    // performance o efficiency (solo en híbridos)
+	CPUs    []int   `json:"cpus"`    // CPU lógicas (hilos) del núcleo
+	MinMHz  float64 `json:"min_mhz"` // Frecuencia mínima (cpufreq)
+	MaxMHz  float64 `json:"max_mhz"` // Frecuencia máxima (cpufreq)
+}
+
+// CPUCache describe un nivel de caché. SizeBytes es el tamaño de cada
+// instancia; SharedBy, cuántas CPU lógicas comparten una instancia.
+type CPUCache struct {
+	Name       string `json:"name"`        // L1d, L1i, L2, L3
+	Level      int    `json:"level"`       // 1, 2, 3
+	Type       string `json:"type"`        // Data, Instruction, Unified
+	SizeBytes  uint64 `json:"size_bytes"`  // Tamaño por instancia
+	Instances  int    `json:"instances"`   // Instancias en el sistema
+	TotalBytes uint64 `json:"total_bytes"` // Tamaño total (todas las instancias)
+	SharedBy   int    `json:"shared_by"`   // CPU lógicas por instancia
+	Ways       int    `json:"ways"`        // Asociatividad
+	LineSize   int    `json:"line_size"`   // Tamaño de línea en bytes
+}
+
+// NUMANode es un nodo NUMA con sus CPU y su memoria local
+type NUMANode struct {
+	ID            int    `json:"id"`              // Número de nodo
+	CPUs          string `json:"cpus"`            // Lista de CPU (ej: 0-23,48-71)
+	MemTotalBytes uint64 `json:"mem_total_bytes"` // Memoria local
+	MemFreeBytes  uint64 `json:"mem_free_bytes"`  // Memoria local libre
 }
 
 // MemoryInfo contiene información de la memoria RAM
@@ -27,6 +148,12 @@ type MemoryInfo struct {
 	TotalGB    float64        `json:"total_gb"`    // Total de RAM en GB
 	TotalBytes uint64         `json:"total_bytes"` // Total de RAM en bytes
 	Modules    []MemoryModule `json:"modules"`     // Módulos de memoria individuales
+
+	// Datos del arreglo de memoria física (SMBIOS tipo 16 y 19)
+	Slots           int    `json:"slots"`            // Número de ranuras
+	MaxCapacity     string `json:"max_capacity"`     // Capacidad máxima soportada
+	ErrorCorrection string `json:"error_correction"` // Tipo de ECC (None, Single-bit ECC...)
+	InstalledBytes  uint64 `json:"installed_bytes"`  // RAM física instalada (incluye la reservada)
 }
 
 // MemoryModule representa un módulo individual de RAM
@@ -37,6 +164,11 @@ type MemoryModule struct {
 	Locator      string `json:"locator"`      // Ubicación física (DIMM1, etc.)
 	Manufacturer string `json:"manufacturer"` // Fabricante
 	PartNumber   string `json:"part_number"`  // Número de parte
+
+	SerialNumber    string `json:"serial_number"`    // Número de serie del módulo
+	FormFactor      string `json:"form_factor"`      // Formato (DIMM, SODIMM, etc.)
+	Rank            int    `json:"rank"`             // Número de rangos (0 = desconocido)
+	ConfiguredSpeed string `json:"configured_speed"` // Velocidad configurada por el BIOS
 }
 
 // MotherboardInfo contiene información de la placa madre
@@ -45,11 +177,71 @@ type MotherboardInfo struct {
 	Product      string `json:"product"`       // Modelo (PRIME B360M-A, etc.)
 	Version      string `json:"version"`       // Versión de la placa
 	SerialNumber string `json:"serial_number"` // Número de serie
+	AssetTag     string `json:"asset_tag"`     // Etiqueta de inventario
 	BIOSVendor   string `json:"bios_vendor"`   // Fabricante del BIOS
 	BIOSVersion  string `json:"bios_version"`  // Versión del BIOS
 	BIOSDate     string `json:"bios_date"`     // Fecha del BIOS
 }
 
+// SystemInfo contiene la identificación del producto y del chasis
+// (SMBIOS tipo 1 y 3)
+type SystemInfo struct {
+	Manufacturer    string `json:"manufacturer"`      // Fabricante del equipo (Dell Inc., LENOVO...)
+	Product         string `json:"product"`           // Nombre del producto (OptiPlex 7050...)
+	Version         string `json:"version"`           // Versión del producto
+	SerialNumber    string `json:"serial_number"`     // Número de serie / service tag
+	UUID            string `json:"uuid"`              // UUID del sistema
+	SKU             string `json:"sku"`               // SKU del producto
+	Family          string `json:"family"`            // Familia del producto
+	ChassisType     string `json:"chassis_type"`      // Tipo de chasis (Desktop, Laptop...)
+	ChassisVendor   string `json:"chassis_vendor"`    // Fabricante del chasis
+	ChassisSerial   string `json:"chassis_serial"`    // Número de serie del chasis
+	ChassisAssetTag string `json:"chassis_asset_tag"` // Etiqueta de inventario del chasis
+}
+
+// FirmwareInfo describe el entorno de arranque del equipo
+type FirmwareInfo struct {
+	BootMode            string      `json:"boot_mode"`             // UEFI o BIOS (heredado)
+	PlatformBits        int         `json:"platform_bits"`         // Firmware UEFI de 64 o 32 bits
+	FirmwareRevision    string      `json:"firmware_revision"`     // Versión del firmware del sistema (ESRT)
+	SecureBootSupported bool        `json:"secure_boot_supported"` // El firmware publica la variable SecureBoot
+	SecureBoot          bool        `json:"secure_boot"`           // Secure Boot activo
+	SetupMode           bool        `json:"setup_mode"`            // Sin clave de plataforma (PK): las claves se pueden reemplazar
+	BootCurrent         string      `json:"boot_current"`          // Entrada usada en este arranque (ej: 0001)
+	BootOrder           []string    `json:"boot_order"`            // Orden de arranque (BootOrder)
+	BootEntries         []BootEntry `json:"boot_entries"`          // Entradas Boot####, en el orden de arranque
+}
+
+// BootEntry es una entrada de arranque UEFI (variable Boot####)
+type BootEntry struct {
+	ID          string `json:"id"`          // Número en hexadecimal (0001)
+	Description string `json:"description"` // ubuntu, Windows Boot Manager...
+	DevicePath  string `json:"device_path"` // Resumen de la ruta: HD(1,GPT,...)/\EFI\ubuntu\shimx64.efi
+	Active      bool   `json:"active"`      // Atributo LOAD_OPTION_ACTIVE
+	Current     bool   `json:"current"`     // Es la entrada del arranque actual
+}
+
+// TPMInfo describe el módulo TPM (Trusted Platform Module) del equipo
+type TPMInfo struct {
+	Present         bool     `json:"present"`          // Hay un TPM con su driver cargado
+	Device          string   `json:"device"`           // tpm0
+	Version         string   `json:"version"`          // Versión de la especificación: 1.2 o 2.0
+	Manufacturer    string   `json:"manufacturer"`     // Infineon, Nuvoton, STMicroelectronics...
+	ManufacturerID  string   `json:"manufacturer_id"`  // Código TCG del fabricante (IFX, NTC, STM...)
+	VendorString    string   `json:"vendor_string"`    // Modelo según el fabricante (solo TPM 2.0)
+	FirmwareVersion string   `json:"firmware_version"` // Versión del firmware del TPM
+	Enabled         *bool    `json:"enabled"`          // Habilitado y activado (nil si no se pudo consultar)
+	Owned           *bool    `json:"owned"`            // Tiene propietario (nil si no se pudo consultar)
+	Banks           []string `json:"pcr_banks"`        // Bancos de PCR asignados (sha1, sha256...)
+	PCRs            []TPMPCR `json:"pcrs_sha256"`      // Valores de los PCR del banco SHA-256
+}
+
+// TPMPCR es el valor de un registro PCR
+type TPMPCR struct {
+	Index int    `json:"index"` // Número de PCR (0-23)
+	Value string `json:"value"` // Digest en hexadecimal
+}
+
 // GPUInfo contiene información de la tarjeta gráfica
 type GPUInfo struct {
 	Vendor     string `json:"vendor"`      // Fabricante (NVIDIA, AMD, Intel)
@@ -57,6 +249,109 @@ type GPUInfo struct {
 	PCIAddress string `json:"pci_address"` // Dirección PCI
 	Driver     string `json:"driver"`      // Driver en uso (si está disponible)
 	MemorySize string `json:"memory_size"` // Tamaño de VRAM (si se puede detectar)
+	VRAMSource string `json:"vram_source"` // Estrategia que obtuvo la VRAM (nvidia-proc, nvidia-smi, drm, pci-bar)
+
+	VBIOSVersion string `json:"vbios_version"` // Versión de la VBIOS (amdgpu, NVIDIA)
+	BootVGA      bool   `json:"boot_vga"`      // GPU que el firmware usó para el arranque
+	PowerState   string `json:"power_state"`   // Estado de energía PCI (D0, D3hot, D3cold)
+	DRMCard      string `json:"drm_card"`      // Nodo DRM principal (card0)
+	DRMRender    string `json:"drm_render"`    // Nodo de render (renderD128)
+
+	LinkSpeed    string `json:"pcie_link_speed"`     // Velocidad actual del enlace PCIe (16.0 GT/s)
+	LinkWidth    int    `json:"pcie_link_width"`     // Carriles actuales del enlace PCIe
+	MaxLinkSpeed string `json:"pcie_max_link_speed"` // Velocidad máxima del enlace PCIe
+	MaxLinkWidth int    `json:"pcie_max_link_width"` // Carriles máximos del enlace PCIe
+
+	BusyPercent int `json:"busy_percent"` // Carga de la GPU en % (-1 si el driver no la publica)
+	CurrentMHz  int `json:"current_mhz"`  // Frecuencia actual del núcleo gráfico
+	MinMHz      int `json:"min_mhz"`      // Frecuencia mínima del núcleo gráfico
+	MaxMHz      int `json:"max_mhz"`      // Frecuencia máxima del núcleo gráfico
+}
+
+// DisplayInfo describe un monitor o panel conectado a un conector de la GPU
+type DisplayInfo struct {
+	Connector       string   `json:"connector"`         // Conector DRM (eDP-1, HDMI-A-1, DP-2...)
+	Card            string   `json:"card"`              // Tarjeta DRM del conector (card0)
+	GPUAddress      string   `json:"gpu_address"`       // Dirección PCI de la GPU
+	Internal        bool     `json:"internal"`          // Panel integrado (eDP, LVDS, DSI)
+	Status          string   `json:"status"`            // Estado del conector (connected)
+	ManufacturerID  string   `json:"manufacturer_id"`   // Código PNP del fabricante (DEL, SAM, AUO...)
+	Manufacturer    string   `json:"manufacturer"`      // Nombre del fabricante (si se conoce el código)
+	Model           string   `json:"model"`             // Nombre del monitor (descriptor de EDID)
+	ProductCode     string   `json:"product_code"`      // Código de producto en hexadecimal
+	SerialNumber    string   `json:"serial_number"`     // Número de serie (texto o numérico)
+	Week            int      `json:"week"`              // Semana de fabricación (0 si no consta)
+	Year            int      `json:"year"`              // Año de fabricación (o del modelo)
+	ModelYear       bool     `json:"model_year"`        // Year es el año del modelo
+	EDIDVersion     string   `json:"edid_version"`      // 1.3, 1.4
+	Interface       string   `json:"interface"`         // Interfaz declarada (DisplayPort, HDMI, DVI)
+	BitDepth        int      `json:"bit_depth"`         // Bits por color (EDID 1.4)
+	WidthMM         int      `json:"width_mm"`          // Ancho de la imagen
+	HeightMM        int      `json:"height_mm"`         // Alto de la imagen
+	DiagonalInches  float64  `json:"diagonal_inches"`   // Diagonal en pulgadas
+	NativeWidth     int      `json:"native_width"`      // Resolución nativa (modo preferido)
+	NativeHeight    int      `json:"native_height"`     // Resolución nativa (modo preferido)
+	NativeRefreshHz float64  `json:"native_refresh_hz"` // Refresco del modo preferido
+	MinRefreshHz    int      `json:"min_refresh_hz"`    // Límites de refresco vertical (0 si no constan)
+	MaxRefreshHz    int      `json:"max_refresh_hz"`    // Límites de refresco vertical (0 si no constan)
+	Features        []string `json:"features"`          // Extensión CTA-861: HDMI, HDR10, HLG, audio...
+}
+
+// PCIDevice representa un dispositivo del bus PCI leído desde sysfs
+type PCIDevice struct {
+	Address     string `json:"address"`             // Dirección PCI (0000:01:00.0)
+	Class       string `json:"class"`               // Código de clase (030000)
+	ClassName   string `json:"class_name"`          // Nombre de la clase (VGA compatible controller)
+	VendorID    string `json:"vendor_id"`           // ID del fabricante (10de)
+	DeviceID    string `json:"device_id"`           // ID del dispositivo (1c03)
+	SubVendorID string `json:"subsystem_vendor_id"` // ID del fabricante de la placa
+	SubDeviceID string `json:"subsystem_device_id"` // ID del modelo de la placa
+	Vendor      string `json:"vendor"`              // Nombre del fabricante (según pci.ids)
+	Device      string `json:"device"`              // Nombre del dispositivo (según pci.ids)
+	Subsystem   string `json:"subsystem"`           // Nombre del subsistema (según pci.ids)
+	Revision    string `json:"revision"`            // Revisión del chip
+	Driver      string `json:"driver"`              // Driver del kernel en uso
+	IOMMUGroup  string `json:"iommu_group"`         // Grupo IOMMU ("" si IOMMU desactivado)
+}
+
+// NetworkInterface describe una interfaz de red de /sys/class/net
+type NetworkInterface struct {
+	Name            string `json:"name"`             // Nombre de la interfaz (eth0, enp3s0, wlan0)
+	MAC             string `json:"mac"`              // Dirección MAC actual
+	PermanentMAC    string `json:"permanent_mac"`    // MAC de fábrica (ethtool; difiere si fue cambiada)
+	Bus             string `json:"bus"`              // Bus del dispositivo padre (pci, usb) o vacío
+	ParentAddress   string `json:"parent_address"`   // Dirección PCI (0000:03:00.0) o USB (1-2)
+	Model           string `json:"model"`            // Nombre del dispositivo padre
+	Driver          string `json:"driver"`           // Driver del kernel (e1000e, iwlwifi)
+	FirmwareVersion string `json:"firmware_version"` // Versión de firmware (ethtool)
+	Carrier         bool   `json:"carrier"`          // Hay enlace físico
+	OperState       string `json:"operstate"`        // Estado operativo (up, down, dormant...)
+	SpeedMbps       int    `json:"speed_mbps"`       // Velocidad del enlace (0 = desconocida o sin enlace)
+	Duplex          string `json:"duplex"`           // full o half
+	MTU             int    `json:"mtu"`              // MTU en bytes
+	Wireless        bool   `json:"wireless"`         // Interfaz Wi-Fi
+	Virtual         bool   `json:"virtual"`          // Sin dispositivo físico (bridge, veth, tun...)
+}
+
+// USBDevice es un nodo del árbol USB leído desde /sys/bus/usb/devices
+type USBDevice struct {
+	Path         string      `json:"path"`         // Nombre en sysfs (usb1, 1-2, 1-2.3)
+	Bus          int         `json:"bus"`          // Número de bus
+	Device       int         `json:"device"`       // Número de dispositivo en el bus
+	VendorID     string      `json:"vendor_id"`    // ID del fabricante (046d)
+	ProductID    string      `json:"product_id"`   // ID del producto (c52b)
+	Vendor       string      `json:"vendor"`       // Fabricante según usb.ids
+	Product      string      `json:"product"`      // Producto según usb.ids
+	Manufacturer string      `json:"manufacturer"` // Fabricante según el propio dispositivo
+	ProductName  string      `json:"product_name"` // Producto según el propio dispositivo
+	Serial       string      `json:"serial"`       // Número de serie
+	Version      string      `json:"usb_version"`  // Versión USB del dispositivo (2.00, 3.20)
+	SpeedMbps    float64     `json:"speed_mbps"`   // Velocidad negociada (1.5, 12, 480, 5000...)
+	MaxPower     string      `json:"max_power"`    // Consumo negociado de la configuración activa (500mA)
+	Class        string      `json:"class"`        // Clase del dispositivo o de sus interfaces
+	Drivers      []string    `json:"drivers"`      // Drivers de sus interfaces (usbhid, usb-storage)
+	IsHub        bool        `json:"is_hub"`       // Es un hub
+	Children     []USBDevice `json:"children"`     // Dispositivos conectados a este hub
 }
 
 // DiskInfo contiene información de un disco de almacenamiento
@@ -66,5 +361,210 @@ type DiskInfo struct {
 	Vendor    string  `json:"vendor"`     // Fabricante
 	SizeGB    float64 `json:"size_gb"`    // Tamaño en GB
 	SizeBytes uint64  `json:"size_bytes"` // Tamaño en bytes
-	Type      string  `json:"type"`       // HDD, SSD, NVMe SSD
+	Type      string  `json:"type"`       // HDD, SSD, NVMe SSD, eMMC, SD, Optical
+	Removable bool    `json:"removable"`  // Medio extraíble (lector de tarjetas, unidad óptica, memoria USB)
+
+	Serial            string `json:"serial"`              // Número de serie
+	WWN               string `json:"wwn"`                 // World Wide Name (NAA o EUI-64)
+	Firmware          string `json:"firmware"`            // Revisión de firmware
+	Transport         string `json:"transport"`           // SATA, SAS, USB, NVMe, MMC, virtio, iSCSI
+	LogicalBlockSize  int    `json:"logical_block_size"`  // Tamaño de bloque lógico en bytes
+	PhysicalBlockSize int    `json:"physical_block_size"` // Tamaño de bloque físico en bytes
+	Discard           bool   `json:"discard"`             // Soporta discard/TRIM
+	NVMeNamespaceID   int    `json:"nvme_nsid"`           // NVMe: ID del espacio de nombres
+	NVMeControllerID  int    `json:"nvme_cntlid"`         // NVMe: ID del controlador
+	ManufactureDate   string `json:"manufacture_date"`    // eMMC/SD: fecha de fabricación del CID (MM/AAAA)
+
+	Optical *OpticalInfo `json:"optical"` // Unidades ópticas (nil en el resto)
+
+	Health *DiskHealth `json:"health"` // Datos SMART (nil si no se pudieron leer)
+
+	PartitionTable   string         `json:"partition_table"`    // gpt, mbr o "" (sin tabla o no leída)
+	PartitionTableID string         `json:"partition_table_id"` // GUID del disco (GPT) o firma del MBR
+	Partitions       []Partition    `json:"partitions"`         // Particiones en orden de número
+	Filesystem       FilesystemInfo `json:"filesystem"`         // Sistema de archivos sobre el disco completo (sin tabla)
+	Contents         []string       `json:"contents"`           // Resumen: Windows, Linux, cifrado LUKS/BitLocker...
+}
+
+// OpticalInfo describe las capacidades de una unidad de CD/DVD
+type OpticalInfo struct {
+	Capabilities []string `json:"capabilities"` // CD-R, CD-RW, DVD, DVD-R, DVD-RAM, MRW, audio...
+	SpeedX       int      `json:"speed_x"`      // Velocidad máxima de lectura (múltiplo de CD, 150 KB/s)
+	Status       string   `json:"status"`       // disc, no disc, tray open, not ready o "" si no se consultó
+}
+
+// PowerInfo describe las fuentes de alimentación del equipo
+type PowerInfo struct {
+	OnAC            bool           `json:"on_ac"`            // Algún adaptador conectado
+	Adapters        []PowerAdapter `json:"adapters"`         // Adaptadores de corriente (Mains, USB-PD...)
+	Batteries       []BatteryInfo  `json:"batteries"`        // Baterías del sistema
+	HealthThreshold float64        `json:"health_threshold"` // Salud mínima de batería en % antes de marcarla
+}
+
+// PowerAdapter es un adaptador de corriente o fuente externa
+type PowerAdapter struct {
+	Name   string `json:"name"`   // AC, ADP1, ucsi-source-psy-...
+	Type   string `json:"type"`   // Mains, USB, UPS, Wireless
+	Online bool   `json:"online"` // Conectado y alimentando
+}
+
+// BatteryInfo describe una batería. Las capacidades están en Wh o, si el
+// driver no informa la tensión de diseño, en mAh (CapacityUnit). Los
+// porcentajes y ciclos desconocidos valen -1.
+type BatteryInfo struct {
+	Name            string  `json:"name"`             // BAT0, BAT1
+	Present         bool    `json:"present"`          // Batería insertada
+	Manufacturer    string  `json:"manufacturer"`     // Fabricante
+	Model           string  `json:"model"`            // Modelo
+	Serial          string  `json:"serial"`           // Número de serie
+	Technology      string  `json:"technology"`       // Química: Li-ion, Li-poly, NiMH...
+	Status          string  `json:"status"`           // Charging, Discharging, Full, Not charging
+	CapacityLevel   string  `json:"capacity_level"`   // Normal, Low, Critical... (si no hay porcentaje)
+	ChargePercent   int     `json:"charge_percent"`   // Carga actual en %
+	CapacityUnit    string  `json:"capacity_unit"`    // Wh o mAh
+	DesignCapacity  float64 `json:"design_capacity"`  // Capacidad de diseño
+	FullCapacity    float64 `json:"full_capacity"`    // Capacidad a plena carga actual
+	CurrentCapacity float64 `json:"current_capacity"` // Carga almacenada ahora
+	DesignVoltageV  float64 `json:"design_voltage_v"` // Tensión mínima de diseño
+	VoltageNowV     float64 `json:"voltage_now_v"`    // Tensión actual
+	HealthPercent   float64 `json:"health_percent"`   // Capacidad a plena carga / de diseño
+	WearPercent     float64 `json:"wear_percent"`     // Desgaste: 100 - salud
+	CycleCount      int     `json:"cycle_count"`      // Ciclos de carga
+	BelowThreshold  bool    `json:"below_threshold"`  // Salud por debajo de PowerInfo.HealthThreshold
+}
+
+// SensorChip agrupa los sensores de un chip hwmon (coretemp, k10temp,
+// nct6775, amdgpu, nvme...)
+type SensorChip struct {
+	Name     string          `json:"name"`     // Nombre del driver (archivo name)
+	Device   string          `json:"device"`   // hwmonN
+	Parent   string          `json:"parent"`   // Dispositivo del que cuelga (dirección PCI, coretemp.0...)
+	Readings []SensorReading `json:"readings"` // Temperaturas, ventiladores, tensiones, corrientes y potencias
+}
+
+// SensorReading es la lectura de un sensor hwmon, convertida a °C, RPM, V, A
+// o W. Los umbrales que el chip no informa quedan en 0.
+type SensorReading struct {
+	ID    string  `json:"id"`    // Identificador estable: hwmonN/temp1
+	Type  string  `json:"type"`  // temperature, fan, voltage, current, power
+	Label string  `json:"label"` // Etiqueta del driver (Package id 0, CPU Fan) o el atributo
+	Value float64 `json:"value"` // Valor actual
+	Unit  string  `json:"unit"`  // °C, RPM, V, A, W
+	Min   float64 `json:"min"`   // Umbral mínimo
+	Max   float64 `json:"max"`   // Umbral máximo
+	Crit  float64 `json:"crit"`  // Umbral crítico
+	Alarm bool    `json:"alarm"` // Alarma activa en el chip o valor por encima del crítico
+}
+
+// SensorTrend es la serie de valores de un sensor durante un muestreo
+type SensorTrend struct {
+	ID     string    `json:"id"`     // hwmonN/temp1 (igual que SensorReading.ID)
+	Chip   string    `json:"chip"`   // Nombre del chip
+	Label  string    `json:"label"`  // Etiqueta del sensor
+	Type   string    `json:"type"`   // temperature, fan, voltage, current, power
+	Unit   string    `json:"unit"`   // °C, RPM, V, A, W
+	Values []float64 `json:"values"` // Valor en cada muestra
+	Min    float64   `json:"min"`    // Mínimo observado
+	Max    float64   `json:"max"`    // Máximo observado
+	Avg    float64   `json:"avg"`    // Media de la serie
+	Crit   float64   `json:"crit"`   // Umbral crítico (0 si no hay)
+	Alarm  bool      `json:"alarm"`  // Alguna muestra tuvo alarma
+}
+
+// StorageTopology describe los dispositivos lógicos construidos sobre los
+// discos: arrays RAID por software (md) y mapeos device-mapper (LVM,
+// dm-crypt, multipath)
+type StorageTopology struct {
+	RAID     []RAIDArray     `json:"raid"`
+	Mappings []DeviceMapping `json:"mappings"`
+}
+
+// RAIDArray describe un array md
+type RAIDArray struct {
+	Name         string       `json:"name"`          // md0, md127
+	Level        string       `json:"level"`         // raid0, raid1, raid5, raid6, raid10, linear
+	State        string       `json:"state"`         // array_state: clean, active, inactive, readonly...
+	Status       string       `json:"status"`        // Estado de los miembros según /proc/mdstat ([UU_])
+	Metadata     string       `json:"metadata"`      // Versión del superbloque (1.2, 0.90, external:imsm)
+	SizeBytes    uint64       `json:"size_bytes"`    // Tamaño del array
+	RaidDisks    int          `json:"raid_disks"`    // Miembros previstos
+	Degraded     int          `json:"degraded"`      // Miembros que faltan
+	SyncAction   string       `json:"sync_action"`   // idle, resync, recover, check, repair, reshape
+	SyncProgress float64      `json:"sync_progress"` // Avance de la operación en % (-1 si no hay ninguna)
+	SyncFinish   string       `json:"sync_finish"`   // Tiempo restante estimado por el kernel (12.3min)
+	Members      []RAIDMember `json:"members"`
+	Disks        []string     `json:"disks"` // Discos físicos (DiskInfo.Name) que lo componen
+}
+
+// RAIDMember es un dispositivo miembro de un array md
+type RAIDMember struct {
+	Device string `json:"device"` // Dispositivo (sda1)
+	Slot   int    `json:"slot"`   // Posición en el array (-1 = repuesto)
+	State  string `json:"state"`  // in_sync, faulty, spare, write_mostly...
+}
+
+// DeviceMapping describe un dispositivo device-mapper (dm-N)
+type DeviceMapping struct {
+	Name      string   `json:"name"`       // dm-0
+	MapName   string   `json:"map_name"`   // Nombre del mapeo (vg-lv, luks-<uuid>)
+	Kind      string   `json:"kind"`       // lvm, crypt, multipath, dmraid, stratis o dm
+	UUID      string   `json:"uuid"`       // UUID del mapeo (con el prefijo del subsistema)
+	SizeBytes uint64   `json:"size_bytes"` // Tamaño del dispositivo
+	Slaves    []string `json:"slaves"`     // Dispositivos sobre los que se construye
+	Holders   []string `json:"holders"`    // Dispositivos construidos sobre él
+	Disks     []string `json:"disks"`      // Discos físicos (DiskInfo.Name) que lo componen
+}
+
+// Partition describe una entrada de la tabla de particiones GPT o MBR
+type Partition struct {
+	Number     int            `json:"number"`      // Número de partición (lógicas MBR desde 5)
+	Name       string         `json:"name"`        // Dispositivo (sda1, nvme0n1p1)
+	StartBytes uint64         `json:"start_bytes"` // Desplazamiento desde el inicio del disco
+	SizeBytes  uint64         `json:"size_bytes"`  // Tamaño en bytes
+	Type       string         `json:"type"`        // GUID de tipo (GPT) o código (MBR, "0x83")
+	TypeName   string         `json:"type_name"`   // Nombre del tipo (EFI System, Linux LVM...)
+	PartLabel  string         `json:"partlabel"`   // GPT: nombre de la partición
+	PartUUID   string         `json:"partuuid"`    // GPT: GUID único de la partición
+	Filesystem FilesystemInfo `json:"filesystem"`  // Contenido detectado por firma
+}
+
+// FilesystemInfo identifica el sistema de archivos o contenedor (LUKS, LVM,
+// RAID) por la firma de su superbloque. Type vacío = no reconocido o no leído.
+type FilesystemInfo struct {
+	Type  string `json:"type"`  // ext4, xfs, btrfs, ntfs, vfat, exfat, swap, crypto_LUKS, bitlocker, LVM2_member, linux_raid_member
+	Label string `json:"label"` // Etiqueta del volumen
+	UUID  string `json:"uuid"`  // UUID o número de serie del volumen (formato de blkid)
+}
+
+// DiskHealth resume el estado SMART de un disco ATA o el log SMART/Health de
+// un NVMe. Los campos que la fuente no informa quedan en 0 (o -1 en los
+// porcentajes).
+type DiskHealth struct {
+	Source              string           `json:"source"`               // ata o nvme
+	Verdict             string           `json:"verdict"`              // PASSED, FAILED o "" si no se pudo determinar
+	PowerOnHours        uint64           `json:"power_on_hours"`       // Horas de uso
+	PowerCycles         uint64           `json:"power_cycles"`         // Ciclos de encendido
+	TemperatureC        int              `json:"temperature_c"`        // Temperatura actual
+	ReallocatedSectors  uint64           `json:"reallocated_sectors"`  // ATA: sectores reasignados (atributo 5)
+	PendingSectors      uint64           `json:"pending_sectors"`      // ATA: sectores pendientes de reasignar (197)
+	UncorrectableErrors uint64           `json:"uncorrectable_errors"` // ATA: errores no corregibles (187 + 198)
+	PercentageUsed      int              `json:"percentage_used"`      // Desgaste estimado (0-100+, -1 = desconocido)
+	AvailableSpare      int              `json:"available_spare"`      // NVMe: reserva disponible % (-1 = desconocido)
+	MediaErrors         uint64           `json:"media_errors"`         // NVMe: errores de integridad de datos
+	CriticalWarning     int              `json:"critical_warning"`     // NVMe: bits de advertencia crítica
+	UnsafeShutdowns     uint64           `json:"unsafe_shutdowns"`     // NVMe: apagados sin aviso
+	DataWrittenBytes    uint64           `json:"data_written_bytes"`   // NVMe: datos escritos
+	Attributes          []SmartAttribute `json:"attributes"`           // ATA: tabla completa de atributos
+}
+
+// SmartAttribute es una entrada de la tabla de atributos SMART de ATA
+type SmartAttribute struct {
+	ID        uint8  `json:"id"`
+	Name      string `json:"name"`
+	PreFail   bool   `json:"prefail"`   // Su fallo predice fallo del disco
+	Value     uint8  `json:"value"`     // Valor normalizado actual
+	Worst     uint8  `json:"worst"`     // Peor valor normalizado registrado
+	Threshold uint8  `json:"threshold"` // Umbral de fallo (0 = nunca falla)
+	Raw       uint64 `json:"raw"`       // Valor bruto (48 bits)
+	Failing   bool   `json:"failing"`   // Value <= Threshold
 }
@@ -0,0 +1,210 @@
+package hardware
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Lexharden/hwscan/internal/ids"
+)
+
+// usbClassHub es la clase USB de los hubs
+const usbClassHub = 0x09
+
+// usbIDs devuelve la base de datos de nombres USB configurada en el detector
+// o, si no hay ninguna, la del sistema/embebida
+func (d *Detector) usbIDs() *ids.Database {
+	if d.USBIDs != nil {
+		return d.USBIDs
+	}
+	return ids.USB()
+}
+
+// detectUSB construye el árbol de dispositivos USB desde /sys/bus/usb/devices.
+// Cada bus aparece como su hub raíz (usb1, usb2...) con los dispositivos
+// conectados como hijos según la cadena de puertos de su nombre (1-2.3 cuelga
+// de 1-2, que cuelga de usb1).
+func (d *Detector) detectUSB() ([]USBDevice, error) {
+	roots := make([]USBDevice, 0)
+
+	entries, err := os.ReadDir(d.path("/sys/bus/usb/devices"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Sin controladora USB (ej: VMs y contenedores)
+			return roots, nil
+		}
+		return roots, err
+	}
+
+	db := d.usbIDs()
+	devices := make(map[string]*USBDevice)
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		// Las interfaces (1-2:1.0) se leen desde su dispositivo
+		if strings.Contains(name, ":") {
+			continue
+		}
+		dev := d.readUSBDevice(db, name, entries)
+		devices[name] = &dev
+		names = append(names, name)
+	}
+
+	// Ordenar por bus y cadena de puertos para que los padres se procesen
+	// antes que sus hijos y los hermanos queden en orden de puerto
+	sort.Slice(names, func(i, j int) bool {
+		return lessUSBPath(usbPortChain(names[i]), usbPortChain(names[j]))
+	})
+
+	// Enlazar de abajo hacia arriba: cada hijo ya tiene sus propios hijos
+	// cuando se copia dentro de su padre
+	for i := len(names) - 1; i >= 0; i-- {
+		name := names[i]
+		parent := usbParent(name)
+		if p, ok := devices[parent]; ok && parent != name {
+			p.Children = append([]USBDevice{*devices[name]}, p.Children...)
+		}
+	}
+	for _, name := range names {
+		if usbParent(name) == name {
+			roots = append(roots, *devices[name])
+		} else if _, ok := devices[usbParent(name)]; !ok {
+			// Padre ausente en sysfs: mostrar el dispositivo en la raíz
+			roots = append(roots, *devices[name])
+		}
+	}
+
+	return roots, nil
+}
+
+// readUSBDevice lee los descriptores de un dispositivo USB y de sus
+// interfaces desde sysfs
+func (d *Detector) readUSBDevice(db *ids.Database, name string, entries []os.DirEntry) USBDevice {
+	base := "/sys/bus/usb/devices/" + name
+	dev := USBDevice{Path: name, Children: make([]USBDevice, 0)}
+
+	if v, err := d.readString(base + "/busnum"); err == nil {
+		dev.Bus, _ = strconv.Atoi(v)
+	}
+	if v, err := d.readString(base + "/devnum"); err == nil {
+		dev.Device, _ = strconv.Atoi(v)
+	}
+
+	vendorID, _ := d.readHexID(base + "/idVendor")
+	productID, _ := d.readHexID(base + "/idProduct")
+	dev.VendorID = formatPCIID(vendorID)
+	dev.ProductID = formatPCIID(productID)
+	dev.Vendor = db.Vendor(uint16(vendorID))
+	dev.Product = db.Device(uint16(vendorID), uint16(productID))
+
+	dev.Manufacturer, _ = d.readString(base + "/manufacturer")
+	dev.ProductName, _ = d.readString(base + "/product")
+	dev.Serial, _ = d.readString(base + "/serial")
+	dev.Version, _ = d.readString(base + "/version")
+	dev.MaxPower, _ = d.readString(base + "/bMaxPower")
+	if v, err := d.readString(base + "/speed"); err == nil {
+		dev.SpeedMbps, _ = strconv.ParseFloat(v, 64)
+	}
+
+	// Clase: la del dispositivo o, si se define por interfaz (0x00), las de
+	// sus interfaces. Los drivers útiles (usbhid, usb-storage) también están
+	// en las interfaces; el del dispositivo suele ser el genérico "usb".
+	deviceClass, _ := d.readHexID(base + "/bDeviceClass")
+	dev.IsHub = deviceClass == usbClassHub
+	var classes []string
+	if deviceClass != 0 {
+		classes = append(classes, db.BaseClass(uint8(deviceClass)))
+	}
+	drivers := make([]string, 0)
+	for _, entry := range entries {
+		iface := entry.Name()
+		if !strings.HasPrefix(iface, name+":") {
+			continue
+		}
+		ibase := "/sys/bus/usb/devices/" + iface
+		if deviceClass == 0 {
+			if c, err := d.readHexID(ibase + "/bInterfaceClass"); err == nil {
+				classes = appendUnique(classes, db.BaseClass(uint8(c)))
+			}
+		}
+		if drv := d.linkBase(ibase + "/driver"); drv != "" {
+			drivers = appendUnique(drivers, drv)
+		}
+	}
+	dev.Class = strings.Join(classes, ", ")
+	dev.Drivers = drivers
+
+	return dev
+}
+
+// appendUnique agrega s a list si no está vacío ni repetido
+func appendUnique(list []string, s string) []string {
+	if s == "" {
+		return list
+	}
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// usbParent devuelve el nombre sysfs del padre de un dispositivo USB:
+// "1-2.3" -> "1-2", "1-2" -> "usb1". Los hubs raíz son su propio padre.
+func usbParent(name string) string {
+	if strings.HasPrefix(name, "usb") {
+		return name
+	}
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[:i]
+	}
+	bus, _, _ := strings.Cut(name, "-")
+	return "usb" + bus
+}
+
+// usbPortChain convierte un nombre sysfs en [bus, puerto, puerto...] para
+// ordenar numéricamente ("usb1" -> [1], "1-2.10" -> [1, 2, 10])
+func usbPortChain(name string) []int {
+	if after, ok := strings.CutPrefix(name, "usb"); ok {
+		bus, _ := strconv.Atoi(after)
+		return []int{bus}
+	}
+	bus, ports, _ := strings.Cut(name, "-")
+	n, _ := strconv.Atoi(bus)
+	chain := []int{n}
+	for _, p := range strings.Split(ports, ".") {
+		n, _ := strconv.Atoi(p)
+		chain = append(chain, n)
+	}
+	return chain
+}
+
+// lessUSBPath compara dos cadenas de puertos; un prefijo va antes
+func lessUSBPath(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// usbDisplayName devuelve el nombre legible de un dispositivo USB: el de
+// usb.ids si se conoce o, si no, los descriptores del propio dispositivo
+func usbDisplayName(dev USBDevice) string {
+	vendor := dev.Vendor
+	if vendor == "" {
+		vendor = dev.Manufacturer
+	}
+	product := dev.Product
+	if product == "" {
+		product = dev.ProductName
+	}
+	name := strings.TrimSpace(vendor + " " + product)
+	if name == "" {
+		return "Device " + dev.VendorID + ":" + dev.ProductID
+	}
+	return name
+}
@@ -0,0 +1,56 @@
+package hardware
+
+import (
+	"sort"
+	"strings"
+)
+
+// Estados de CPUVulnerability.Status
+const (
+	VulnNotAffected = "Not affected"
+	VulnMitigated   = "Mitigated"
+	VulnVulnerable  = "Vulnerable"
+	VulnUnknown     = "Unknown"
+)
+
+// readCPUVulnerabilities lee el estado que el kernel informa para cada
+// vulnerabilidad conocida en /sys/devices/system/cpu/vulnerabilities. Cada
+// archivo contiene "Not affected", "Mitigation: <detalle>", "Vulnerable" o
+// "Vulnerable: <detalle>"; itlb_multihit antepone "KVM: " o informa
+// "Processor vulnerable".
+func (d *Detector) readCPUVulnerabilities() []CPUVulnerability {
+	vulns := make([]CPUVulnerability, 0)
+	base := "/sys/devices/system/cpu/vulnerabilities"
+	for _, name := range d.listDir(base) {
+		text, err := d.readString(base + "/" + name)
+		if err != nil {
+			continue
+		}
+		status, detail := vulnerabilityStatus(text)
+		vulns = append(vulns, CPUVulnerability{Name: name, Status: status, Detail: detail})
+	}
+	sort.Slice(vulns, func(i, j int) bool { return vulns[i].Name < vulns[j].Name })
+	return vulns
+}
+
+// vulnerabilityStatus clasifica el texto del kernel en un estado y su detalle
+// (la mitigación aplicada o el motivo)
+func vulnerabilityStatus(text string) (status, detail string) {
+	// itlb_multihit describe la mitigación de KVM: "KVM: Mitigation: Split
+	// huge pages", "KVM: Vulnerable"
+	text = strings.TrimPrefix(text, "KVM: ")
+	switch {
+	case text == "Processor vulnerable":
+		return VulnVulnerable, ""
+	case strings.HasPrefix(text, "Not affected"):
+		return VulnNotAffected, ""
+	case strings.HasPrefix(text, "Mitigation"):
+		_, detail, _ = strings.Cut(text, ":")
+		return VulnMitigated, strings.TrimSpace(detail)
+	case strings.HasPrefix(text, "Vulnerable"):
+		detail = strings.TrimPrefix(text, "Vulnerable")
+		return VulnVulnerable, strings.TrimSpace(strings.TrimLeft(detail, ":;"))
+	}
+	_, detail, _ = strings.Cut(text, ":")
+	return VulnUnknown, strings.TrimSpace(detail)
+}
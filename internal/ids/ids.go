@@ -0,0 +1,301 @@
+// Package ids resuelve nombres de fabricantes, dispositivos y clases a partir
+// de bases de datos con el formato de pci.ids (https://pci-ids.ucw.cz/) y
+// usb.ids (http://www.linux-usb.org/usb.ids).
+//
+// El binario embebe una copia comprimida de cada base de datos; si el sistema
+// tiene instalada una versión completa (paquete hwdata) se usa esa en su lugar.
+//
+// Las copias de pci.ids y usb.ids incluidas en el repositorio son
+// subconjuntos: los fabricantes y dispositivos más comunes y la lista
+// completa de clases. "make update-ids" las reemplaza por las bases completas
+// (unos cientos de KB comprimidas); conviene ejecutarlo antes de compilar
+// para el sistema live de Alpine, que no trae hwdata.
+package ids
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed pci.ids.gz
+var embeddedPCI []byte
+
+// pciSystemPaths son las ubicaciones habituales de pci.ids en distribuciones Linux
+var pciSystemPaths = []string{
+	"/usr/share/hwdata/pci.ids",
+	"/usr/share/misc/pci.ids",
+	"/usr/share/pci.ids",
+}
+
+//go:embed usb.ids.gz
+var embeddedUSB []byte
+
+// usbSystemPaths son las ubicaciones habituales de usb.ids en distribuciones Linux
+var usbSystemPaths = []string{
+	"/usr/share/hwdata/usb.ids",
+	"/usr/share/misc/usb.ids",
+	"/var/lib/usbutils/usb.ids",
+	"/usr/share/usb.ids",
+}
+
+// Database contiene los nombres de fabricantes, dispositivos, subsistemas y
+// clases de una base de datos de IDs
+type Database struct {
+	vendors map[uint16]*vendor
+	classes map[uint8]*class
+}
+
+type vendor struct {
+	name    string
+	devices map[uint16]*device
+}
+
+type device struct {
+	name       string
+	subsystems map[uint32]string // clave: subvendor<<16 | subdevice
+}
+
+type class struct {
+	name       string
+	subclasses map[uint8]*subclass
+}
+
+type subclass struct {
+	name    string
+	progIfs map[uint8]string
+}
+
+var (
+	pciOnce sync.Once
+	pciDB   *Database
+
+	usbOnce sync.Once
+	usbDB   *Database
+)
+
+// PCI devuelve la base de datos PCI por defecto: la del sistema si existe,
+// o la embebida en el binario. Se carga una única vez.
+func PCI() *Database {
+	pciOnce.Do(func() {
+		pciDB = loadDefault(pciSystemPaths, embeddedPCI)
+	})
+	return pciDB
+}
+
+// USB devuelve la base de datos USB por defecto: la del sistema si existe,
+// o la embebida en el binario. Se carga una única vez.
+func USB() *Database {
+	usbOnce.Do(func() {
+		usbDB = loadDefault(usbSystemPaths, embeddedUSB)
+	})
+	return usbDB
+}
+
+// loadDefault carga la primera base de datos legible de paths o, si ninguna
+// existe, la copia embebida comprimida
+func loadDefault(paths []string, embedded []byte) *Database {
+	for _, path := range paths {
+		if db, err := Load(path); err == nil {
+			return db
+		}
+	}
+
+	db, err := parseCompressed(embedded)
+	if err != nil {
+		// La copia embebida siempre debería ser válida; devolver una base
+		// vacía permite seguir mostrando los IDs numéricos
+		return &Database{}
+	}
+	return db
+}
+
+// Load carga una base de datos desde disco. Los archivos terminados en .gz
+// se descomprimen automáticamente.
+func Load(path string) (*Database, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasSuffix(path, ".gz") {
+		return parseCompressed(data)
+	}
+	return Parse(bytes.NewReader(data))
+}
+
+// parseCompressed descomprime y parsea una base de datos gzip
+func parseCompressed(data []byte) (*Database, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return Parse(zr)
+}
+
+// Parse lee una base de datos con el formato de pci.ids/usb.ids.
+// Las secciones que no son de fabricantes ni de clases se ignoran.
+func Parse(r io.Reader) (*Database, error) {
+	db := &Database{
+		vendors: make(map[uint16]*vendor),
+		classes: make(map[uint8]*class),
+	}
+
+	var (
+		curVendor   *vendor
+		curDevice   *device
+		curClass    *class
+		curSubclass *subclass
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		depth := 0
+		for depth < len(line) && line[depth] == '\t' {
+			depth++
+		}
+		id, name := splitEntry(line[depth:])
+
+		switch depth {
+		case 0:
+			curVendor, curDevice, curClass, curSubclass = nil, nil, nil, nil
+			if strings.HasPrefix(line, "C ") {
+				cid, cname := splitEntry(line[2:])
+				if v, ok := parseHex(cid, 8); ok {
+					curClass = &class{name: cname, subclasses: make(map[uint8]*subclass)}
+					db.classes[uint8(v)] = curClass
+				}
+			} else if v, ok := parseHex(id, 16); ok {
+				curVendor = &vendor{name: name, devices: make(map[uint16]*device)}
+				db.vendors[uint16(v)] = curVendor
+			}
+			// Otras secciones (ej: "AT", "HID" en usb.ids) quedan sin contexto
+
+		case 1:
+			if curVendor != nil {
+				if v, ok := parseHex(id, 16); ok {
+					curDevice = &device{name: name}
+					curVendor.devices[uint16(v)] = curDevice
+				}
+			} else if curClass != nil {
+				if v, ok := parseHex(id, 8); ok {
+					curSubclass = &subclass{name: name, progIfs: make(map[uint8]string)}
+					curClass.subclasses[uint8(v)] = curSubclass
+				}
+			}
+
+		case 2:
+			if curDevice != nil {
+				// Subsistema "subvendor subdevice  nombre"
+				fields := strings.Fields(line)
+				if len(fields) < 3 {
+					continue
+				}
+				sv, ok1 := parseHex(fields[0], 16)
+				sd, ok2 := parseHex(fields[1], 16)
+				if !ok1 || !ok2 {
+					continue // ej: interfaces de usb.ids
+				}
+				if curDevice.subsystems == nil {
+					curDevice.subsystems = make(map[uint32]string)
+				}
+				_, subName := splitEntry(strings.TrimSpace(line[depth+len(fields[0]):]))
+				curDevice.subsystems[uint32(sv)<<16|uint32(sd)] = subName
+			} else if curSubclass != nil {
+				if v, ok := parseHex(id, 8); ok {
+					curSubclass.progIfs[uint8(v)] = name
+				}
+			}
+		}
+	}
+
+	return db, scanner.Err()
+}
+
+// splitEntry separa "id  nombre" en sus dos partes
+func splitEntry(s string) (string, string) {
+	id, name, _ := strings.Cut(s, " ")
+	return id, strings.TrimSpace(name)
+}
+
+// parseHex interpreta un ID hexadecimal de bits bits
+func parseHex(s string, bits int) (uint64, bool) {
+	if len(s) != bits/4 {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(s, 16, bits)
+	return v, err == nil
+}
+
+// Vendor devuelve el nombre del fabricante o "" si no se conoce
+func (db *Database) Vendor(vendorID uint16) string {
+	if v := db.vendors[vendorID]; v != nil {
+		return v.name
+	}
+	return ""
+}
+
+// Device devuelve el nombre del dispositivo o "" si no se conoce
+func (db *Database) Device(vendorID, deviceID uint16) string {
+	if v := db.vendors[vendorID]; v != nil {
+		if d := v.devices[deviceID]; d != nil {
+			return d.name
+		}
+	}
+	return ""
+}
+
+// Subsystem devuelve el nombre del subsistema (ej: el modelo de la tarjeta
+// de un fabricante concreto) o "" si no se conoce
+func (db *Database) Subsystem(vendorID, deviceID, subVendorID, subDeviceID uint16) string {
+	if v := db.vendors[vendorID]; v != nil {
+		if d := v.devices[deviceID]; d != nil {
+			return d.subsystems[uint32(subVendorID)<<16|uint32(subDeviceID)]
+		}
+	}
+	return ""
+}
+
+// Class devuelve el nombre más específico conocido de la clase: el de la
+// subclase si existe, o el de la clase base
+func (db *Database) Class(classID, subclassID uint8) string {
+	c := db.classes[classID]
+	if c == nil {
+		return ""
+	}
+	if s := c.subclasses[subclassID]; s != nil {
+		return s.name
+	}
+	return c.name
+}
+
+// BaseClass devuelve el nombre de la clase base, sin considerar la subclase
+// (ej: "Hub" para la clase USB 0x09) o "" si no se conoce
+func (db *Database) BaseClass(classID uint8) string {
+	if c := db.classes[classID]; c != nil {
+		return c.name
+	}
+	return ""
+}
+
+// ProgIf devuelve el nombre de la interfaz de programación (ej: "XHCI",
+// "NVM Express") o "" si no se conoce
+func (db *Database) ProgIf(classID, subclassID, progIf uint8) string {
+	if c := db.classes[classID]; c != nil {
+		if s := c.subclasses[subclassID]; s != nil {
+			return s.progIfs[progIf]
+		}
+	}
+	return ""
+}
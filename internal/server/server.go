@@ -6,6 +6,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/Lexharden/hwscan/internal/hardware"
@@ -13,16 +14,26 @@ import (
 	"github.com/Lexharden/hwscan/internal/version"
 )
 
+// Límites del muestreo de /api/sensors: la respuesta debe llegar antes del
+// WriteTimeout del servidor
+const (
+	maxSensorSamples  = 10
+	maxSensorDuration = 8 * time.Second
+)
+
 // Server representa el servidor HTTP embebido
 type Server struct {
 	hardwareInfo *hardware.HardwareInfo
+	detector     *hardware.Detector
 	port         int
 }
 
-// New crea una nueva instancia del servidor
-func New(info *hardware.HardwareInfo, port int) *Server {
+// New crea una nueva instancia del servidor. El detector se usa para leer
+// los sensores en vivo desde /api/sensors.
+func New(info *hardware.HardwareInfo, detector *hardware.Detector, port int) *Server {
 	return &Server{
 		hardwareInfo: info,
+		detector:     detector,
 		port:         port,
 	}
 }
@@ -34,6 +45,9 @@ func (s *Server) Start() error {
 	// Endpoint API para obtener información de hardware
 	mux.HandleFunc("/api/hardware", s.handleHardwareAPI)
 
+	// Endpoint de sensores en vivo
+	mux.HandleFunc("/api/sensors", s.handleSensors)
+
 	// Endpoint de salud
 	mux.HandleFunc("/api/health", s.handleHealth)
 
@@ -101,6 +115,54 @@ func (s *Server) handleHardwareAPI(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleSensors maneja las peticiones al endpoint /api/sensors: toma
+// ?samples=N muestras de los sensores separadas por ?interval=duración
+// (por defecto una sola muestra) y devuelve la serie de cada sensor
+func (s *Server) handleSensors(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	samples := 1
+	if v := r.URL.Query().Get("samples"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxSensorSamples {
+			http.Error(w, fmt.Sprintf("samples debe estar entre 1 y %d", maxSensorSamples), http.StatusBadRequest)
+			return
+		}
+		samples = n
+	}
+	interval := time.Second
+	if v := r.URL.Query().Get("interval"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			http.Error(w, "interval inválido", http.StatusBadRequest)
+			return
+		}
+		interval = d
+	}
+	// Se divide en lugar de multiplicar para que un interval enorme no
+	// desborde la duración total y se salte el límite
+	if samples > 1 && interval > maxSensorDuration/time.Duration(samples-1) {
+		http.Error(w, fmt.Sprintf("el muestreo no puede durar más de %s", maxSensorDuration), http.StatusBadRequest)
+		return
+	}
+
+	series, err := s.detector.SampleSensors(r.Context(), samples, interval)
+	if err != nil {
+		http.Error(w, "Error al leer sensores", http.StatusInternalServerError)
+		log.Printf("Error al leer sensores: %v\n", err)
+		return
+	}
+
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(series); err != nil {
+		log.Printf("Error al serializar sensores: %v\n", err)
+	}
+}
+
 // handleHealth maneja las peticiones al endpoint /api/health
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")